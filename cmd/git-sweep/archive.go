@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gitpkg "github.com/jmelosegui/git-sweep/internal/git"
+	sweeppkg "github.com/jmelosegui/git-sweep/internal/sweep"
+	pflag "github.com/spf13/pflag"
+)
+
+// runArchive implements `git sweep archive list|restore|expire`.
+func runArchive(args []string) {
+	fs := pflag.NewFlagSet("archive", pflag.ContinueOnError)
+	olderThan := fs.String("older-than", "90d", "expire archive entries older than this age (e.g. 90d, 12w)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	rest := fs.Args()
+	if len(rest) == 0 {
+		printArchiveUsage()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	r := gitpkg.ExecRunner{}
+
+	switch rest[0] {
+	case "list":
+		entries, err := sweeppkg.ListArchive(ctx, r)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		if len(entries) == 0 {
+			fmt.Println("archive is empty")
+			return
+		}
+		for _, a := range entries {
+			fmt.Printf("  %s  %s  %s\n", gitpkg.ShortSHA(a.Tip), a.Name, a.Ref)
+		}
+	case "restore":
+		if len(rest) < 2 {
+			printArchiveUsage()
+			return
+		}
+		for _, name := range rest[1:] {
+			a, err := sweeppkg.RestoreArchived(ctx, r, name)
+			if err != nil {
+				fmt.Printf("error: %s: %v\n", name, err)
+				continue
+			}
+			fmt.Printf("Restored %s at %s (from %s)\n", name, gitpkg.ShortSHA(a.Tip), a.Ref)
+		}
+	case "expire":
+		age, err := sweeppkg.ParseAge(*olderThan)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		expired, err := sweeppkg.ExpireArchive(ctx, r, age, time.Now())
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		fmt.Printf("Expired %d archived branch(es)\n", len(expired))
+		for _, a := range expired {
+			fmt.Printf("  - %s\n", a.Ref)
+		}
+	default:
+		printArchiveUsage()
+	}
+}
+
+func printArchiveUsage() {
+	fmt.Println("usage: git sweep archive list")
+	fmt.Println("   or: git sweep archive restore <branch>...")
+	fmt.Println("   or: git sweep archive expire [--older-than <age>]")
+	fmt.Println()
+	fmt.Println("        --older-than <age>  expire entries archived before this age (default: 90d)")
+}
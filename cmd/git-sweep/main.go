@@ -23,7 +23,25 @@
 	version = "v0.0.0-dev"
 )
 
+// subcommands maps `git sweep <name>` to its implementation; anything else is
+// treated as the default sweep.
+var subcommands = map[string]func(args []string){
+	"archive": runArchive,
+	"undo":    runUndo,
+	"restore": runRestore,
+	"recover": runRecover,
+	"tags":    runTags,
+	"refs":    runRefs,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			run(os.Args[2:])
+			return
+		}
+	}
+
 	// GNU-style flags via pflag
 	var (
 		showHelp    bool
@@ -33,6 +51,23 @@ func main() {
 		exclude     string
 		jsonOut     bool
 		yes         bool
+		force       bool
+		atomic      bool
+		archive     string
+		bundle      string
+		rescue      string
+		recentDays  int
+		staleDays   int
+		mine        bool
+		author      string
+		withStashed bool
+		withOrphans bool
+		switchOff   bool
+		syncAll     bool
+		retrack     bool
+		repoint     bool
+		pruneConfig bool
+		pruneRemote bool
 	)
 
 	pflag.BoolVarP(&showHelp, "help", "h", false, "show help")
@@ -42,6 +77,24 @@ func main() {
 	pflag.StringVarP(&exclude, "exclude", "x", "", "regex to exclude branch names")
 	pflag.BoolVarP(&jsonOut, "json", "j", false, "print plan as JSON")
 	pflag.BoolVarP(&yes, "yes", "y", false, "execute deletions (otherwise dry-run)")
+	pflag.BoolVarP(&force, "force", "f", false, "delete branches that are not proven merged or pushed")
+	pflag.BoolVar(&atomic, "atomic", false, "delete all candidates or none")
+	pflag.StringVar(&archive, "archive", "", "move branches to refs/sweep-archive/<date>/ (or archive/ tags with =tag) instead of deleting")
+	pflag.Lookup("archive").NoOptDefVal = string(sweeppkg.ArchiveRefs)
+	pflag.StringVar(&bundle, "bundle", "", "write swept branches to a git bundle first")
+	pflag.StringVar(&rescue, "rescue-remote", "", "push gone branches with unpushed commits to rescue/<user>/<branch> on this remote first")
+	pflag.IntVar(&recentDays, "protect-recent", 1, "protect branches checked out within the last N days (0 to disable)")
+	pflag.IntVar(&staleDays, "stale", 0, "also sweep branches not checked out for N days")
+	pflag.BoolVar(&mine, "mine", false, "only sweep branches authored by user.email")
+	pflag.StringVar(&author, "author", "", "only sweep branches whose commits match the author regex")
+	pflag.BoolVar(&withStashed, "include-stashed", false, "sweep branches referenced by stash entries (flagged in the plan)")
+	pflag.BoolVar(&withOrphans, "include-unrelated", false, "sweep branches that share no history with the default branch (e.g. gh-pages)")
+	pflag.BoolVar(&switchOff, "switch", false, "when the current branch is gone, switch to the default branch and sweep it too")
+	pflag.BoolVar(&syncAll, "sync", false, "after sweeping, fast-forward branches that are behind their upstream")
+	pflag.BoolVar(&retrack, "retrack", false, "re-point branches whose upstream was renamed to the suggested upstream")
+	pflag.BoolVar(&repoint, "repoint-stacked", false, "re-point branches stacked on a swept branch to the default branch")
+	pflag.BoolVar(&pruneConfig, "prune-config", false, "remove branch.<name> config sections of branches that no longer exist")
+	pflag.BoolVar(&pruneRemote, "prune-removed-remotes", false, "remove remote-tracking refs of remotes that are no longer configured")
 	pflag.Parse()
 
 	if showHelp {
@@ -61,17 +114,36 @@ func main() {
 	updateResult := startUpdateCheck(jsonOut)
 	defer printUpdateNotice(updateResult)
 
+	archiveMode, err := sweeppkg.ParseArchiveMode(archive)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if syncAll && jsonOut {
+		fmt.Println("error: --sync cannot be combined with --json")
+		return
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
 	r := gitpkg.ExecRunner{}
 	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{
-		Remote:          remote,
-		IncludePattern:  include,
-		ExcludePattern:  exclude,
-		ExtraProtected:  nil,
-		ProtectCurrent:  true,
-		ProtectUpstream: true,
+		Remote:           remote,
+		IncludePattern:   include,
+		ExcludePattern:   exclude,
+		ExtraProtected:   nil,
+		ProtectCurrent:   true,
+		ProtectUpstream:  true,
+		ProtectRecent:    days(recentDays),
+		StaleAfter:       days(staleDays),
+		Mine:             mine,
+		AuthorPattern:    author,
+		ProtectStashed:   !withStashed,
+		ProtectUnrelated: !withOrphans,
+		PruneConfig:      pruneConfig,
+		PruneRemotes:     pruneRemote,
+		Switch:           switchOff,
 	})
 	if err != nil {
 		if errors.Is(err, gitpkg.ErrNotGitRepository) {
@@ -88,31 +160,127 @@ func main() {
 		return
 	}
 
-	if count == 0 {
+	if plan.Operation.Name != "" {
 		return
 	}
 
-	// Interactive confirm if not --yes
-	if !yes {
-		ok, err := uipkg.ConfirmDeletion(count)
+	// Deletions and --sync only change anything with --yes or an interactive yes.
+	apply := yes
+	retracking := retrack && len(plan.Renamed) > 0
+	pruning := len(plan.OrphanedConfigs) > 0
+	removedRemotes := len(plan.RemovedRemotes) > 0
+	syncing := syncAll && yes
+	if syncAll && !yes {
+		// Show what --sync would move so that the prompt covers it.
+		preview, err := sweeppkg.Sync(ctx, r, true)
 		if err != nil {
 			fmt.Println("error:", err)
 			return
 		}
-		if !ok {
+		if err := uipkg.PrintSyncResult(preview, true); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		syncing = len(preview.Updated) > 0
+	}
+	if count > 0 || (!jsonOut && (plan.DefaultRename != nil || retracking || pruning || removedRemotes || syncing)) {
+		if !yes {
+			question := fmt.Sprintf("Proceed with deleting %d branch(es)?", count)
+			if count == 0 {
+				question = "Proceed with the changes listed above?"
+			}
+			ok, err := uipkg.Confirm(question)
+			if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+			apply = ok
+		}
+		if apply && !executeSweep(ctx, r, plan, sweeppkg.ExecuteOptions{
+			MaxParallel:       0,
+			ForceDelete:       force,
+			Atomic:            atomic,
+			Archive:           archiveMode,
+			Journal:           true,
+			CommandLine:       os.Args,
+			BundlePath:        bundle,
+			RescueRemote:      rescue,
+			RepointDependents: repoint,
+		}) {
+			return
+		}
+	}
+
+	if retracking && apply {
+		failed := sweeppkg.Retrack(ctx, r, plan.Renamed)
+		for _, b := range plan.Renamed {
+			if err, ok := failed[b.Name]; ok {
+				fmt.Printf("error: re-tracking %s: %v\n", b.Name, err)
+				continue
+			}
+			fmt.Printf("Branch '%s' now tracks '%s'\n", b.Name, b.SuggestedUpstream)
+		}
+	}
+
+	if pruning && apply {
+		failed := sweeppkg.PruneBranchConfigs(ctx, r, plan.OrphanedConfigs)
+		for _, name := range plan.OrphanedConfigs {
+			if err, ok := failed[name]; ok {
+				fmt.Printf("error: removing branch.%s: %v\n", name, err)
+				continue
+			}
+			fmt.Printf("Removed config section 'branch.%s'\n", name)
+		}
+	}
+
+	if removedRemotes && apply {
+		if err := sweeppkg.PruneRemovedRemotes(ctx, r, plan.RemovedRemotes); err != nil {
+			fmt.Println("error: removing refs of removed remotes:", err)
+		} else {
+			for _, rm := range plan.RemovedRemotes {
+				fmt.Printf("Removed %d ref(s) of removed remote '%s'\n", len(rm.Refs), rm.Name)
+			}
+		}
+	}
+
+	if syncing && apply {
+		syncRes, err := sweeppkg.Sync(ctx, r, false)
+		if err != nil {
+			fmt.Println("error:", err)
 			return
 		}
+		if err := uipkg.PrintSyncResult(syncRes, false); err != nil {
+			fmt.Println("error:", err)
+		}
 	}
+}
 
-	// Execute deletions; --yes or confirmed implies force-delete (-D)
-	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{MaxParallel: 0, ForceDelete: true})
+// executeSweep deletes the planned branches and prints the outcome. Only
+// branches proven safe (or all with --force) are deleted, and only if they
+// still point where they did when the plan was built. It reports whether the
+// sweep ran.
+func executeSweep(ctx context.Context, r gitpkg.Runner, plan sweeppkg.Plan, opts sweeppkg.ExecuteOptions) bool {
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, opts)
 	if err != nil {
 		fmt.Println("error:", err)
-		return
+		return false
 	}
 	if err := uipkg.PrintDeletionResult(res); err != nil {
 		fmt.Println("error:", err)
+		return false
 	}
+	if len(res.Bundled) > 0 {
+		fmt.Printf("Bundled %d branch(es) into %s\n", len(res.Bundled), opts.BundlePath)
+	}
+	if len(res.Deleted) > 0 {
+		fmt.Println("(use \"git sweep undo\" to restore them)")
+	}
+	return true
+}
+
+// days converts a day count from the command line into a duration.
+func days(n int) time.Duration {
+	return time.Duration(n) * 24 * time.Hour
 }
 
 // startUpdateCheck runs the GitHub release lookup in the background and
@@ -170,12 +338,42 @@ func isStderrTTY() bool {
 
 func printUsage() {
 	fmt.Println("usage: git sweep [<options>]")
+	fmt.Println("   or: git sweep <subcommand> [<args>]")
 	fmt.Println()
 	fmt.Println("    -V, --version           print version and exit")
 	fmt.Println("    -r, --remote <name>     git remote to use for fetch --prune (default: origin)")
 	fmt.Println("    -i, --include <regex>   include branches matching regex")
 	fmt.Println("    -x, --exclude <regex>   exclude branches matching regex")
 	fmt.Println("    -j, --json              machine-readable plan output (JSON)")
-	fmt.Println("    -y, --yes               execute deletions (consent to force-delete -D)")
+	fmt.Println("    -y, --yes               execute deletions (otherwise dry-run)")
+	fmt.Println("    -f, --force             delete branches not proven merged or pushed")
+	fmt.Println("        --atomic            delete all candidates or none")
+	fmt.Println("        --archive[=tag]     move branches to refs/sweep-archive/<date>/ (or archive/ tags)")
+	fmt.Println("        --bundle <file>     write swept branches to a bundle first")
+	fmt.Println("        --rescue-remote <name>")
+	fmt.Println("                            push unpushed work of gone branches to rescue/<user>/<branch> first")
+	fmt.Println("        --protect-recent <n> protect branches checked out within the last n days")
+	fmt.Println("                            (default: 1; 0 disables it)")
+	fmt.Println("        --stale <n>         also sweep branches not checked out for n days")
+	fmt.Println("        --mine              only sweep branches whose unique commits are yours (user.email)")
+	fmt.Println("        --author <regex>    only sweep branches whose unique commits match the author regex")
+	fmt.Println("        --include-stashed   sweep branches referenced by stash entries (protected by default)")
+	fmt.Println("        --include-unrelated sweep orphan branches with no history shared with the default branch")
+	fmt.Println("        --switch            switch off a gone current branch (clean worktree) and sweep it")
+	fmt.Println("        --sync              fast-forward branches strictly behind their upstream afterwards")
+	fmt.Println("        --retrack           follow renamed upstreams (branch --set-upstream-to) for branches listed as renamed")
+	fmt.Println("        --repoint-stacked   re-point branches stacked on swept branches to the default branch")
+	fmt.Println("        --prune-config      remove branch.<name> config sections of branches that no longer exist")
+	fmt.Println("        --prune-removed-remotes")
+	fmt.Println("                            remove remote-tracking refs of remotes that are no longer configured")
 	fmt.Println("    -h, --help              show this help")
+	fmt.Println()
+	fmt.Println("subcommands:")
+	fmt.Println("    archive list|restore|expire   manage archived branches")
+	fmt.Println("    undo                          restore the branches deleted by the last run")
+	fmt.Println("    restore <branch>...           restore branches from the deletion journal")
+	fmt.Println("    restore --from-bundle <file>  restore branches from a bundle written by --bundle")
+	fmt.Println("    recover [<n|branch>...]       find (or recreate) branches deleted without a journal")
+	fmt.Println("    tags [-r|-i|-x|-j|-y]         sweep local tags that do not exist on the remote")
+	fmt.Println("    refs [<namespace>...]         sweep refs such as refs/pull/* by age or absence on a remote")
 }
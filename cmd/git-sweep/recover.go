@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	gitpkg "github.com/jmelosegui/git-sweep/internal/git"
+	sweeppkg "github.com/jmelosegui/git-sweep/internal/sweep"
+	pflag "github.com/spf13/pflag"
+)
+
+// runRecover implements `git sweep recover`: list likely tips of branches
+// deleted without a journal, and recreate the ones selected by number or name.
+func runRecover(args []string) {
+	fs := pflag.NewFlagSet("recover", pflag.ContinueOnError)
+	sinceFlag := fs.String("since", "30d", "only consider branches last seen within this age (e.g. 30d, 12w)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	age, err := sweeppkg.ParseAge(*sinceFlag)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 120*time.Second)
+	defer cancel()
+	r := gitpkg.ExecRunner{}
+
+	found, err := sweeppkg.FindRecoverable(ctx, r, time.Now().Add(-age))
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	selected := fs.Args()
+	if len(selected) == 0 {
+		if len(found) == 0 {
+			fmt.Println("No deleted branches found.")
+			return
+		}
+		fmt.Println("Recoverable branches:")
+		for i, c := range found {
+			fmt.Printf("  %d. %s  %s  %s  %s (%s)\n", i+1, c.Name, gitpkg.ShortSHA(c.Tip), c.Date.Format("2006-01-02"), c.Subject, c.Source)
+		}
+		fmt.Println("(use \"git sweep recover <n|branch>...\" to recreate them)")
+		return
+	}
+
+	var picks []sweeppkg.Recoverable
+	for _, sel := range selected {
+		c, ok := pickRecoverable(found, sel)
+		if !ok {
+			fmt.Printf("error: %s: no such recoverable branch\n", sel)
+			continue
+		}
+		picks = append(picks, c)
+	}
+	if len(picks) == 0 {
+		return
+	}
+	res, err := sweeppkg.Recover(ctx, r, picks)
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	printRestoreResult(res)
+}
+
+// pickRecoverable resolves a 1-based list number or a branch name.
+func pickRecoverable(found []sweeppkg.Recoverable, sel string) (sweeppkg.Recoverable, bool) {
+	if n, err := strconv.Atoi(sel); err == nil && n >= 1 && n <= len(found) {
+		return found[n-1], true
+	}
+	for _, c := range found {
+		if c.Name == sel {
+			return c, true
+		}
+	}
+	return sweeppkg.Recoverable{}, false
+}
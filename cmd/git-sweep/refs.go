@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gitpkg "github.com/jmelosegui/git-sweep/internal/git"
+	sweeppkg "github.com/jmelosegui/git-sweep/internal/sweep"
+	uipkg "github.com/jmelosegui/git-sweep/internal/ui"
+	pflag "github.com/spf13/pflag"
+)
+
+// runRefs implements `git sweep refs`: sweep refs in namespaces such as
+// refs/pull/ by age or by absence on a remote. Namespaces given on the command
+// line use the flags; otherwise the [sweepref "<namespace>"] config applies.
+func runRefs(args []string) {
+	fs := pflag.NewFlagSet("refs", pflag.ContinueOnError)
+	olderThan := fs.String("older-than", "", "sweep refs whose commit is older than this age (e.g. 30d, 12w)")
+	missingOn := fs.String("missing-on", "", "sweep refs that do not exist on this remote")
+	remotePrefix := fs.String("remote-prefix", "", "namespace on the remote matching the local one (default: the same)")
+	jsonOut := fs.BoolP("json", "j", false, "print plan as JSON")
+	yes := fs.BoolP("yes", "y", false, "execute deletions (otherwise dry-run)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	r := gitpkg.ExecRunner{}
+
+	var rules []sweeppkg.NamespaceRule
+	if namespaces := fs.Args(); len(namespaces) > 0 {
+		var age time.Duration
+		if *olderThan != "" {
+			var err error
+			if age, err = sweeppkg.ParseAge(*olderThan); err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+		}
+		for _, ns := range namespaces {
+			rules = append(rules, sweeppkg.NamespaceRule{Prefix: ns, OlderThan: age, Remote: *missingOn, RemotePrefix: *remotePrefix})
+		}
+	} else {
+		var err error
+		if rules, err = sweeppkg.NamespaceRules(ctx, r); err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+	}
+	if len(rules) == 0 {
+		printRefsUsage()
+		return
+	}
+
+	plan, err := sweeppkg.BuildRefPlan(ctx, r, rules, time.Now())
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	count, err := uipkg.PrintRefPlan(plan, uipkg.Options{JSON: *jsonOut})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+	if !*yes {
+		ok, err := uipkg.Confirm(fmt.Sprintf("Proceed with deleting %d ref(s)?", count))
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	if err := sweeppkg.DeleteStaleRefs(ctx, r, plan); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if err := uipkg.PrintRefDeletionResult(plan); err != nil {
+		fmt.Println("error:", err)
+	}
+}
+
+func printRefsUsage() {
+	fmt.Println("usage: git sweep refs <namespace>... [--older-than <age>] [--missing-on <remote>] [-y]")
+	fmt.Println("   or: git sweep refs [-y]   (uses [sweepref \"<namespace>\"] sections from git config)")
+	fmt.Println()
+	fmt.Println("        --older-than <age>      sweep refs whose commit is older than age (e.g. 30d)")
+	fmt.Println("        --missing-on <remote>   sweep refs that do not exist on the remote")
+	fmt.Println("        --remote-prefix <ns>    remote namespace matching the local one (default: the same)")
+	fmt.Println("    -j, --json                  machine-readable plan output (JSON)")
+	fmt.Println("    -y, --yes                   execute deletions (otherwise dry-run)")
+}
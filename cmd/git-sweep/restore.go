@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gitpkg "github.com/jmelosegui/git-sweep/internal/git"
+	sweeppkg "github.com/jmelosegui/git-sweep/internal/sweep"
+	pflag "github.com/spf13/pflag"
+)
+
+// runUndo implements `git sweep undo`: recreate the branches of the last run.
+func runUndo(args []string) {
+	if len(args) != 0 {
+		fmt.Println("usage: git sweep undo")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	res, err := sweeppkg.Undo(ctx, gitpkg.ExecRunner{})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	printRestoreResult(res)
+}
+
+// runRestore implements `git sweep restore <branch>...` from the journal, or
+// from a bundle written by --bundle with `--from-bundle <file>`.
+func runRestore(args []string) {
+	fs := pflag.NewFlagSet("restore", pflag.ContinueOnError)
+	bundle := fs.String("from-bundle", "", "restore from a bundle written by --bundle (all branches when none are named)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	names := fs.Args()
+	if len(names) == 0 && *bundle == "" {
+		fmt.Println("usage: git sweep restore <branch>...")
+		fmt.Println("   or: git sweep restore --from-bundle <file> [<branch>...]")
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	r := gitpkg.ExecRunner{}
+	if *bundle != "" {
+		res, err := sweeppkg.RestoreFromBundle(ctx, r, *bundle, names)
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		printRestoreResult(res)
+		return
+	}
+	for _, name := range names {
+		res, err := sweeppkg.RestoreFromJournal(ctx, r, name)
+		if err != nil {
+			fmt.Println("error:", err)
+			continue
+		}
+		printRestoreResult(res)
+	}
+}
+
+func printRestoreResult(res sweeppkg.RestoreResult) {
+	for _, b := range res.Restored {
+		upstream := ""
+		if b.Upstream != "" {
+			upstream = fmt.Sprintf(", tracking '%s'", b.Upstream)
+		}
+		fmt.Printf("Restored %s at %s%s\n", b.Name, gitpkg.ShortSHA(b.Tip), upstream)
+	}
+	for _, b := range res.Skipped {
+		fmt.Printf("Skipped %s: branch already exists\n", b.Name)
+	}
+}
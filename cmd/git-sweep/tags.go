@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gitpkg "github.com/jmelosegui/git-sweep/internal/git"
+	sweeppkg "github.com/jmelosegui/git-sweep/internal/sweep"
+	uipkg "github.com/jmelosegui/git-sweep/internal/ui"
+	pflag "github.com/spf13/pflag"
+)
+
+// runTags implements `git sweep tags`: list local tags that do not exist on
+// the remote and delete them with --yes or on confirmation.
+func runTags(args []string) {
+	fs := pflag.NewFlagSet("tags", pflag.ContinueOnError)
+	remote := fs.StringP("remote", "r", "origin", "git remote whose tags are compared")
+	include := fs.StringP("include", "i", "", "regex to include tag names")
+	exclude := fs.StringP("exclude", "x", "", "regex to exclude tag names")
+	jsonOut := fs.BoolP("json", "j", false, "print plan as JSON")
+	yes := fs.BoolP("yes", "y", false, "execute deletions (otherwise dry-run)")
+	if err := fs.Parse(args); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+	r := gitpkg.ExecRunner{}
+
+	plan, err := sweeppkg.BuildTagPlan(ctx, r, sweeppkg.TagOptions{
+		Remote:         *remote,
+		IncludePattern: *include,
+		ExcludePattern: *exclude,
+	})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	count, err := uipkg.PrintTagPlan(plan, uipkg.Options{JSON: *jsonOut})
+	if err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if count == 0 {
+		return
+	}
+	if !*yes {
+		ok, err := uipkg.Confirm(fmt.Sprintf("Proceed with deleting %d tag(s)?", count))
+		if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+	if err := sweeppkg.DeleteTags(ctx, r, plan); err != nil {
+		fmt.Println("error:", err)
+		return
+	}
+	if err := uipkg.PrintTagDeletionResult(plan); err != nil {
+		fmt.Println("error:", err)
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// UniqueAuthors returns the "Name <email>" identities of the commits that are
+// reachable only from the given local branch, not from any other local branch
+// or remote-tracking ref. When the branch has no unique commits, the author of
+// its tip commit is returned instead so fully merged branches still have an owner.
+// It runs: git log --format=%an <%ae> refs/heads/<branch> --not --exclude=<branch> --branches --remotes
+func UniqueAuthors(ctx context.Context, r Runner, branch string) ([]string, error) {
+	ref := "refs/heads/" + branch
+	res, err := r.Run(ctx, "log", "--format=%an <%ae>", ref, "--not", "--exclude="+escapeGlob(branch), "--branches", "--remotes")
+	if err != nil {
+		return nil, err
+	}
+	authors := parseAuthors(res.Stdout)
+	if len(authors) > 0 {
+		return authors, nil
+	}
+	res, err = r.Run(ctx, "log", "-1", "--format=%an <%ae>", ref)
+	if err != nil {
+		return nil, err
+	}
+	return parseAuthors(res.Stdout), nil
+}
+
+// escapeGlob escapes the wildmatch metacharacters in name so that an --exclude
+// pattern matches exactly that name.
+func escapeGlob(name string) string {
+	var b strings.Builder
+	for _, c := range name {
+		if strings.ContainsRune(`\*?[`, c) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// parseAuthors returns the distinct non-empty lines of output in first-seen order.
+func parseAuthors(output string) []string {
+	seen := make(map[string]struct{})
+	var out []string
+	for _, ln := range strings.Split(output, "\n") {
+		ln = strings.TrimSpace(ln)
+		if ln == "" {
+			continue
+		}
+		if _, ok := seen[ln]; ok {
+			continue
+		}
+		seen[ln] = struct{}{}
+		out = append(out, ln)
+	}
+	return out
+}
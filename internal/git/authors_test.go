@@ -0,0 +1,17 @@
+package git
+
+import "testing"
+
+func TestEscapeGlob(t *testing.T) {
+	cases := map[string]string{
+		"feature/x":   "feature/x",
+		"fix-*":       `fix-\*`,
+		"what?":       `what\?`,
+		"[wip]/topic": `\[wip]/topic`,
+	}
+	for in, want := range cases {
+		if got := escapeGlob(in); got != want {
+			t.Errorf("escapeGlob(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -1,15 +1,47 @@
 package git
 
+import "time"
+
+// headsPrefix is the namespace of local branch refs.
+const headsPrefix = "refs/heads/"
+
 // Branch represents a local branch and basic information about its upstream.
-// Upstream may be empty if none is configured.
+// Ref and UpstreamRef are fully qualified (e.g., "refs/heads/feature/foo",
+// "refs/remotes/origin/main") and are what comparisons and deletions use.
+// Name and Upstream are short names for display only (e.g., "feature/foo",
+// "origin/main"); Name is Ref without "refs/heads/", so it is never ambiguous.
+// Upstream and UpstreamRef may be empty if none is configured.
 // Track contains Git's tracking status string (e.g., "[gone]", "[ahead 1]", "[behind 2]").
 // IsGone is true when the upstream remote ref has been deleted ("[gone]").
-// The Name and Upstream are short names (e.g., "feature/foo", "origin/main").
+// Tip is the commit SHA the branch points to; it may be empty when discovered
+// through the `git branch -vv` fallback.
+// LastCheckout is when the branch was last checked out according to the HEAD
+// reflog; it is the zero time when unknown or not looked up.
+// Stashes lists the stash entries (e.g., "stash@{0}") made on or based on the branch.
+// SuggestedUpstream is set for a gone branch whose upstream seems to have been
+// renamed: another remote branch (e.g., "origin/feature/new-name") points at
+// or contains its tip.
+// SymrefTarget is the full ref a symbolic branch points to (e.g., "x" created
+// with `git symbolic-ref refs/heads/x refs/heads/y`); it is empty for ordinary
+// branches. Tip is then the target's commit.
+// MissingRemote is the remote named by branch.<name>.remote when that remote
+// is no longer configured; such a branch has no Upstream and is treated as gone.
+// Unrelated is set when the branch shares no history with the remote default
+// branch, as orphan branches such as gh-pages do.
 //
 //nolint:revive // exported fields with clear descriptive names
 type Branch struct {
-	Name     string
-	Upstream string
-	Track    string
-	IsGone   bool
+	Ref               string
+	Name              string
+	UpstreamRef       string
+	Upstream          string
+	Track             string
+	IsGone            bool
+	Tip               string
+	LastCheckout      time.Time
+	Stashes           []string
+	SuggestedUpstream string
+	SymrefTarget      string
+	MissingRemote     string
+	Unrelated         bool
 }
@@ -0,0 +1,92 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// CreateBundle writes refs into a bundle at path. Every ref is included: git
+// leaves out a ref whose tip the excluded history reaches, so only history
+// below the tips' common merge base is excluded, or below the parents of that
+// base when it is itself one of the tips. Branches without common history are
+// bundled in full.
+// It runs: git bundle create <path> <refs>... --not <base>...
+func CreateBundle(ctx context.Context, r Runner, path string, refs []RefCreation) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	args := []string{"bundle", "create", path}
+	tips := make([]string, 0, len(refs))
+	isTip := make(map[string]bool, len(refs))
+	for _, ref := range refs {
+		args = append(args, ref.Ref)
+		tips = append(tips, ref.Tip)
+		isTip[ref.Tip] = true
+	}
+	bases, err := octopusMergeBases(ctx, r, tips)
+	if err != nil {
+		return err
+	}
+	if len(bases) > 0 {
+		args = append(args, "--not")
+		for _, base := range bases {
+			if isTip[base] {
+				base += "^@"
+			}
+			args = append(args, base)
+		}
+	}
+	_, err = r.Run(ctx, args...)
+	return err
+}
+
+// octopusMergeBases returns the best common ancestors of all commits, or none
+// when they share no history.
+// It runs: git merge-base --octopus --all <commits>...
+func octopusMergeBases(ctx context.Context, r Runner, commits []string) ([]string, error) {
+	res, err := r.Run(ctx, append([]string{"merge-base", "--octopus", "--all"}, commits...)...)
+	if err != nil {
+		if res.ExitCode == 1 && strings.TrimSpace(res.Stderr) == "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return strings.Fields(res.Stdout), nil
+}
+
+// BundleHeads lists the refs stored in a bundle.
+// It runs: git bundle list-heads <path>
+func BundleHeads(ctx context.Context, r Runner, path string) ([]RefCreation, error) {
+	res, err := r.Run(ctx, "bundle", "list-heads", path)
+	if err != nil {
+		return nil, err
+	}
+	var heads []RefCreation
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		tip, ref, ok := strings.Cut(strings.TrimSpace(ln), " ")
+		if !ok {
+			continue
+		}
+		heads = append(heads, RefCreation{Ref: ref, Tip: tip})
+	}
+	return heads, nil
+}
+
+// VerifyBundle checks that a bundle is valid and that its prerequisite
+// commits exist in the repository.
+// It runs: git bundle verify -q <path>
+func VerifyBundle(ctx context.Context, r Runner, path string) error {
+	_, err := r.Run(ctx, "bundle", "verify", "-q", path)
+	return err
+}
+
+// FetchRefs fetches refspecs (e.g., "refs/heads/x:refs/heads/x") from source,
+// which may be a remote name, URL or bundle path.
+// It runs: git fetch <source> <refspecs>...
+func FetchRefs(ctx context.Context, r Runner, source string, refspecs []string) error {
+	if len(refspecs) == 0 {
+		return nil
+	}
+	_, err := r.Run(ctx, append([]string{"fetch", source}, refspecs...)...)
+	return err
+}
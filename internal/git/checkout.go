@@ -0,0 +1,61 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// WorktreeClean reports whether the worktree and index have no changes to
+// tracked files. Untracked files do not count.
+// It runs: git status --porcelain --untracked-files=no
+func WorktreeClean(ctx context.Context, r Runner) (bool, error) {
+	res, err := r.Run(ctx, "status", "--porcelain", "--untracked-files=no")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(res.Stdout) == "", nil
+}
+
+// Checkout switches the worktree to an existing local branch.
+// It runs: git checkout <name> --
+func Checkout(ctx context.Context, r Runner, name string) error {
+	_, err := r.Run(ctx, "checkout", name, "--")
+	return err
+}
+
+// CheckoutTracking creates branch name at startRef, tracking it, and switches
+// to it.
+// It runs: git checkout -b <name> --track <startRef>
+func CheckoutTracking(ctx context.Context, r Runner, name, startRef string) error {
+	_, err := r.Run(ctx, "checkout", "-b", name, "--track", startRef)
+	return err
+}
+
+// FastForward fast-forwards the current branch to ref, failing when the branch
+// has diverged from it.
+// It runs: git merge --ff-only <ref>
+func FastForward(ctx context.Context, r Runner, ref string) error {
+	res, err := r.Run(ctx, "merge", "--ff-only", ref)
+	if err != nil {
+		if msg, _, _ := strings.Cut(strings.TrimSpace(res.Stderr), "\n"); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// RenameBranch renames a local branch along with its config and reflog.
+// It runs: git branch -m <oldName> <newName>
+func RenameBranch(ctx context.Context, r Runner, oldName, newName string) error {
+	_, err := r.Run(ctx, "branch", "-m", oldName, newName)
+	return err
+}
+
+// SetUpstream sets the upstream of a local branch, e.g. to "origin/main".
+// It runs: git branch --set-upstream-to=<upstream> <branch>
+func SetUpstream(ctx context.Context, r Runner, branch, upstream string) error {
+	_, err := r.Run(ctx, "branch", "--set-upstream-to="+upstream, branch)
+	return err
+}
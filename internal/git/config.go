@@ -0,0 +1,153 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// ConfigValue returns the value of a single git config key (e.g., "user.email").
+// It runs: git config --get <key>
+// A missing key returns an empty string and nil error.
+func ConfigValue(ctx context.Context, r Runner, key string) (string, error) {
+	res, err := r.Run(ctx, "config", "--get", key)
+	if err != nil {
+		if res.ExitCode == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// ConfigEntry is one variable of a config section, e.g. {"remote", "origin"}
+// for branch.<name>.remote.
+type ConfigEntry struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// BranchConfig returns the `branch.<name>.*` entries of the repository config
+// keyed by branch name, in config file order.
+// It runs: git config --local -z --get-regexp ^branch\.
+func BranchConfig(ctx context.Context, r Runner) (map[string][]ConfigEntry, error) {
+	res, err := r.Run(ctx, "config", "--local", "-z", "--get-regexp", `^branch\.`)
+	if err != nil {
+		if res.ExitCode == 1 {
+			return map[string][]ConfigEntry{}, nil
+		}
+		return nil, err
+	}
+	return parseBranchConfig(res.Stdout), nil
+}
+
+// SubsectionConfig returns the `<section>.<subsection>.*` entries of the
+// config (all scopes) keyed by subsection, in config file order. Variable names
+// are lowercase, as git reports them.
+// It runs: git config -z --get-regexp ^<section>\.
+func SubsectionConfig(ctx context.Context, r Runner, section string) (map[string][]ConfigEntry, error) {
+	res, err := r.Run(ctx, "config", "-z", "--get-regexp", `^`+section+`\.`)
+	if err != nil {
+		if res.ExitCode == 1 {
+			return map[string][]ConfigEntry{}, nil
+		}
+		return nil, err
+	}
+	return parseSubsections(res.Stdout, section), nil
+}
+
+// parseBranchConfig parses `-z --get-regexp` output for branch sections.
+func parseBranchConfig(output string) map[string][]ConfigEntry {
+	return parseSubsections(output, "branch")
+}
+
+// parseSubsections parses `-z --get-regexp` output: records end with NUL and
+// the key is separated from the value by a newline.
+func parseSubsections(output, section string) map[string][]ConfigEntry {
+	out := make(map[string][]ConfigEntry)
+	for _, rec := range strings.Split(output, "\x00") {
+		key, value, _ := strings.Cut(rec, "\n")
+		// <section>.<name>.<var>; the name itself may contain dots.
+		rest, ok := strings.CutPrefix(key, section+".")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(rest, ".")
+		if i <= 0 {
+			continue
+		}
+		name := rest[:i]
+		out[name] = append(out[name], ConfigEntry{Key: rest[i+1:], Value: value})
+	}
+	return out
+}
+
+// FetchDestinations returns the local side of every fetch refspec in the
+// config: remote.<name>.fetch, and the fetch, branches and tags refspecs of
+// git-svn's svn-remote.<name> sections, e.g. "refs/remotes/origin/*" or
+// "refs/remotes/git-svn". Refspecs without a destination are skipped.
+// It runs: git config -z --get-regexp ^(remote\..*\.fetch|svn-remote\..*\.(fetch|branches|tags))$
+func FetchDestinations(ctx context.Context, r Runner) ([]string, error) {
+	res, err := r.Run(ctx, "config", "-z", "--get-regexp", `^(remote\..*\.fetch|svn-remote\..*\.(fetch|branches|tags))$`)
+	if err != nil {
+		if res.ExitCode == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return parseFetchDestinations(res.Stdout), nil
+}
+
+func parseFetchDestinations(output string) []string {
+	var dsts []string
+	for _, rec := range strings.Split(output, "\x00") {
+		_, spec, ok := strings.Cut(rec, "\n")
+		if !ok {
+			continue
+		}
+		i := strings.LastIndex(spec, ":")
+		if i < 0 || i == len(spec)-1 {
+			continue
+		}
+		dsts = append(dsts, spec[i+1:])
+	}
+	return dsts
+}
+
+// BranchConfigNames returns the names of branches that have a
+// `branch.<name>.*` section in the repository config.
+func BranchConfigNames(ctx context.Context, r Runner) (map[string]bool, error) {
+	cfg, err := BranchConfig(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(cfg))
+	for name := range cfg {
+		names[name] = true
+	}
+	return names, nil
+}
+
+// AddBranchConfig appends entries to the `branch.<name>` config section.
+// It runs: git config --local --add branch.<name>.<key> <value> for each entry.
+func AddBranchConfig(ctx context.Context, r Runner, name string, entries []ConfigEntry) error {
+	for _, e := range entries {
+		_, err := retryOnLock(ctx, func() (Result, error) {
+			return r.Run(ctx, "config", "--local", "--add", "branch."+name+"."+e.Key, e.Value)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveBranchConfig removes the `branch.<name>` section from the repository
+// config, as `git branch -d` does when it deletes a branch.
+// It runs: git config --local --remove-section branch.<name>
+// Lock contention on the config file is retried with backoff.
+func RemoveBranchConfig(ctx context.Context, r Runner, name string) error {
+	_, err := retryOnLock(ctx, func() (Result, error) {
+		return r.Run(ctx, "config", "--local", "--remove-section", "branch."+name)
+	})
+	return err
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseBranchConfig(t *testing.T) {
+	input := "" +
+		"branch.feature/x.remote\norigin\x00" +
+		"branch.feature/x.merge\nrefs/heads/feature/x\x00" +
+		"branch.v1.2.description\nline one\nline two\x00"
+
+	got := parseBranchConfig(input)
+	want := map[string][]ConfigEntry{
+		"feature/x": {{Key: "remote", Value: "origin"}, {Key: "merge", Value: "refs/heads/feature/x"}},
+		"v1.2":      {{Key: "description", Value: "line one\nline two"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+}
+
+func TestParseFetchDestinations(t *testing.T) {
+	input := "" +
+		"remote.origin.fetch\n+refs/heads/*:refs/remotes/origin/*\x00" +
+		"remote.mirror.fetch\nrefs/heads/main\x00" +
+		"svn-remote.svn.fetch\ntrunk:refs/remotes/git-svn\x00" +
+		"svn-remote.svn.branches\nbranches/*:refs/remotes/svn/*\x00"
+
+	got := parseFetchDestinations(input)
+	want := []string{"refs/remotes/origin/*", "refs/remotes/git-svn", "refs/remotes/svn/*"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
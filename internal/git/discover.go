@@ -20,9 +20,10 @@ func FetchPrune(ctx context.Context, r Runner, remote string) error {
 // ListLocalBranches returns local branches with their upstream and tracking status.
 // Prefer `for-each-ref` for structured output; fallback to parsing `git branch -vv` if needed.
 func ListLocalBranches(ctx context.Context, r Runner) ([]Branch, error) {
-	// Try for-each-ref with a custom format capturing: name, upstream, and upstream:track
-	// %1: short refname; %2: upstream short; %3: upstream:track status
-	format := "%(refname:short)\t%(upstream:short)\t%(upstream:track)"
+	// for-each-ref with NUL-separated fields so no refname or status can be split wrongly:
+	// full refname, full upstream, short upstream (display only), upstream:track, object name,
+	// symref target (empty unless the branch is a symbolic ref to another branch)
+	format := "%(refname)%00%(upstream)%00%(upstream:short)%00%(upstream:track)%00%(objectname)%00%(symref)"
 	res, err := r.Run(ctx, "for-each-ref", "--format="+format, "refs/heads")
 	if err == nil && strings.TrimSpace(res.Stdout) != "" {
 		return parseForEachRef(res.Stdout), nil
@@ -39,18 +40,20 @@ func parseForEachRef(output string) []Branch {
 	var branches []Branch
 	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
 	for _, ln := range lines {
-		parts := strings.SplitN(ln, "\t", 3)
-		if len(parts) < 3 {
+		parts := strings.Split(ln, "\x00")
+		if len(parts) != 6 || !strings.HasPrefix(parts[0], headsPrefix) {
 			continue
 		}
-		name := strings.TrimSpace(parts[0])
-		upstream := strings.TrimSpace(parts[1])
-		track := strings.TrimSpace(parts[2])
+		track := parts[3]
 		branches = append(branches, Branch{
-			Name:     name,
-			Upstream: upstream,
-			Track:    track,
-			IsGone:   strings.Contains(track, "[gone]"),
+			Ref:          parts[0],
+			Name:         strings.TrimPrefix(parts[0], headsPrefix),
+			UpstreamRef:  parts[1],
+			Upstream:     parts[2],
+			Track:        track,
+			IsGone:       strings.Contains(track, "[gone]"),
+			Tip:          parts[4],
+			SymrefTarget: parts[5],
 		})
 	}
 	return branches
@@ -69,6 +72,7 @@ func parseBranchVV(output string) []Branch {
 		branch := strings.TrimSpace(m[1])
 		track := strings.TrimSpace(m[2])
 		branches = append(branches, Branch{
+			Ref:      headsPrefix + branch,
 			Name:     branch,
 			Upstream: "", // Not available from -vv reliably without more parsing
 			Track:    track,
@@ -4,9 +4,9 @@
 
 func TestParseForEachRef(t *testing.T) {
 	input := "" +
-		"feature/foo\torigin/main\t[ahead 1]\n" +
-		"bugfix/bar\torigin/main\t[behind 2]\n" +
-		"old/baz\torigin/main\t[gone]\n"
+		"refs/heads/feature/foo\x00refs/remotes/origin/main\x00origin/main\x00[ahead 1]\x001111\x00\n" +
+		"refs/heads/bugfix/bar\x00refs/remotes/origin/main\x00origin/main\x00[behind 2]\x002222\x00\n" +
+		"refs/heads/old/baz\x00refs/remotes/origin/old/baz\x00origin/old/baz\x00[gone]\x00cafe0000\x00\n"
 
 	branches := parseForEachRef(input)
 	if len(branches) != 3 {
@@ -15,6 +15,36 @@ func TestParseForEachRef(t *testing.T) {
 	if !branches[2].IsGone {
 		t.Fatalf("expected third branch to be gone, got %+v", branches[2])
 	}
+	if branches[2].Tip != "cafe0000" {
+		t.Fatalf("expected tip cafe0000, got %q", branches[2].Tip)
+	}
+	if branches[2].Ref != "refs/heads/old/baz" || branches[2].Name != "old/baz" {
+		t.Fatalf("unexpected ref/name: %+v", branches[2])
+	}
+	if branches[2].UpstreamRef != "refs/remotes/origin/old/baz" || branches[2].Upstream != "origin/old/baz" {
+		t.Fatalf("unexpected upstream: %+v", branches[2])
+	}
+}
+
+func TestParseForEachRef_AmbiguousShortName(t *testing.T) {
+	// A local branch literally named origin/main keeps its unambiguous name.
+	input := "refs/heads/origin/main\x00\x00\x00\x00abcd\x00\n"
+
+	branches := parseForEachRef(input)
+	if len(branches) != 1 || branches[0].Name != "origin/main" || branches[0].Ref != "refs/heads/origin/main" {
+		t.Fatalf("unexpected branches: %+v", branches)
+	}
+}
+
+func TestParseForEachRef_Symref(t *testing.T) {
+	input := "" +
+		"refs/heads/alias\x00\x00\x00\x00abcd\x00refs/heads/main\n" +
+		"refs/heads/main\x00refs/remotes/origin/main\x00origin/main\x00\x00abcd\x00\n"
+
+	branches := parseForEachRef(input)
+	if len(branches) != 2 || branches[0].SymrefTarget != "refs/heads/main" || branches[1].SymrefTarget != "" {
+		t.Fatalf("unexpected branches: %+v", branches)
+	}
 }
 
 func TestParseBranchVV(t *testing.T) {
@@ -5,6 +5,7 @@
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os/exec"
 	"strings"
 )
@@ -21,10 +22,20 @@ type ExecRunner struct {
 // Run executes `git` with the provided arguments. Output is captured and returned in Result.
 // When git exits non-zero, an error is returned and Result.ExitCode is set when available.
 func (r ExecRunner) Run(ctx context.Context, args ...string) (Result, error) {
+	return r.run(ctx, nil, args)
+}
+
+// RunInput executes `git` like Run, feeding input to its standard input.
+func (r ExecRunner) RunInput(ctx context.Context, input string, args ...string) (Result, error) {
+	return r.run(ctx, strings.NewReader(input), args)
+}
+
+func (r ExecRunner) run(ctx context.Context, stdin io.Reader, args []string) (Result, error) {
 	cmd := exec.CommandContext(ctx, "git", args...)
 	if r.WorkDir != "" {
 		cmd.Dir = r.WorkDir
 	}
+	cmd.Stdin = stdin
 
 	var stdoutBuf, stderrBuf bytes.Buffer
 	cmd.Stdout = &stdoutBuf
@@ -5,24 +5,31 @@
 	"strings"
 )
 
-// CurrentBranch returns the current branch short name (e.g., "main").
+// CurrentBranch returns the current branch short name (e.g., "main"), derived
+// from the full ref HEAD points to so it is never disambiguated by git.
 // If in detached HEAD, it returns "HEAD".
+// It runs: git symbolic-ref -q HEAD
 func CurrentBranch(ctx context.Context, r Runner) (string, error) {
-	res, err := r.Run(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	res, err := r.Run(ctx, "symbolic-ref", "-q", "HEAD")
 	if err != nil {
+		if res.ExitCode == 1 {
+			return "HEAD", nil
+		}
 		return "", err
 	}
-	return strings.TrimSpace(res.Stdout), nil
+	return strings.TrimPrefix(strings.TrimSpace(res.Stdout), headsPrefix), nil
 }
 
-// BranchUpstream returns the short upstream name for the given branch (e.g., "origin/main").
-// If the branch has no upstream, it returns an empty string and nil error.
-func BranchUpstream(ctx context.Context, r Runner, branch string) (string, error) {
-	// Use symbolic-full-name to ensure we get short form; on some setups, --abbrev-ref shortens it
-	res, err := r.Run(ctx, "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}")
+// HeadCommit returns the commit SHA that HEAD resolves to.
+// In a repository without commits (unborn HEAD) it returns an empty string and nil error.
+// It runs: git rev-parse --verify -q HEAD
+func HeadCommit(ctx context.Context, r Runner) (string, error) {
+	res, err := r.Run(ctx, "rev-parse", "--verify", "-q", "HEAD")
 	if err != nil {
-		// No upstream configured or other error; treat as no-upstream when output empty
-		return "", nil
+		if res.ExitCode == 1 && strings.TrimSpace(res.Stderr) == "" {
+			return "", nil
+		}
+		return "", err
 	}
 	return strings.TrimSpace(res.Stdout), nil
 }
@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// lockRetryDelays are the pauses between attempts when another git process
+// holds a lock file (packed-refs.lock, a ref's .lock, or config.lock).
+// About 1.5s in total is enough for an IDE's background fetch to finish.
+var lockRetryDelays = []time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	400 * time.Millisecond,
+	800 * time.Millisecond,
+}
+
+// isLockContention reports whether git failed because a lock file is held,
+// as opposed to a real conflict such as a ref not having its expected value.
+func isLockContention(stderr string) bool {
+	return strings.Contains(stderr, ".lock': File exists") ||
+		strings.Contains(stderr, "could not lock config file")
+}
+
+// retryOnLock runs run and repeats it with backoff while git reports lock
+// contention. Any other outcome, or context cancellation, ends the retries.
+func retryOnLock(ctx context.Context, run func() (Result, error)) (Result, error) {
+	res, err := run()
+	for _, d := range lockRetryDelays {
+		if err == nil || !isLockContention(res.Stderr) {
+			return res, err
+		}
+		select {
+		case <-ctx.Done():
+			return res, err
+		case <-time.After(d):
+		}
+		res, err = run()
+	}
+	return res, err
+}
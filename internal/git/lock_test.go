@@ -0,0 +1,43 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryOnLock(t *testing.T) {
+	saved := lockRetryDelays
+	lockRetryDelays = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	defer func() { lockRetryDelays = saved }()
+
+	locked := Result{Stderr: "fatal: Unable to create '/repo/.git/packed-refs.lock': File exists.", ExitCode: 128}
+	conflict := Result{Stderr: "fatal: cannot lock ref 'refs/heads/x': is at aaa but expected bbb", ExitCode: 128}
+	fail := errors.New("exit status 128")
+
+	cases := []struct {
+		name      string
+		results   []Result
+		wantCalls int
+		wantErr   bool
+	}{
+		{"succeeds after contention", []Result{locked, locked, {}}, 3, false},
+		{"gives up after retries", []Result{locked, locked, locked, locked, locked}, 4, true},
+		{"does not retry real conflicts", []Result{conflict, {}}, 1, true},
+	}
+	for _, c := range cases {
+		calls := 0
+		_, err := retryOnLock(context.Background(), func() (Result, error) {
+			res := c.results[calls]
+			calls++
+			if res.ExitCode != 0 {
+				return res, fail
+			}
+			return res, nil
+		})
+		if calls != c.wantCalls || (err != nil) != c.wantErr {
+			t.Errorf("%s: calls=%d err=%v, want calls=%d err=%v", c.name, calls, err, c.wantCalls, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit holds display information about a commit.
+type Commit struct {
+	SHA     string
+	Time    time.Time
+	Subject string
+}
+
+// ShortSHA abbreviates a commit SHA for display.
+func ShortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// IsFullSHA reports whether s is a full-length SHA-1 or SHA-256 object name.
+func IsFullSHA(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	return strings.Trim(s, "0123456789abcdef") == ""
+}
+
+// DanglingCommits returns commits that no ref or reflog reaches.
+// It runs: git fsck --dangling --no-progress
+func DanglingCommits(ctx context.Context, r Runner) ([]string, error) {
+	res, err := r.Run(ctx, "fsck", "--dangling", "--no-progress")
+	if err != nil {
+		return nil, err
+	}
+	var shas []string
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		if sha, ok := strings.CutPrefix(strings.TrimSpace(ln), "dangling commit "); ok {
+			shas = append(shas, sha)
+		}
+	}
+	return shas, nil
+}
+
+// CommitInfo returns the committer date and subject of the given commits,
+// keyed by SHA. Objects that are missing or are not commits are omitted.
+// It runs: git cat-file --batch-check, then git show -s --no-walk
+func CommitInfo(ctx context.Context, r Runner, shas []string) (map[string]Commit, error) {
+	out := make(map[string]Commit)
+	if len(shas) == 0 {
+		return out, nil
+	}
+	res, err := RunInput(ctx, r, strings.Join(shas, "\n")+"\n", "cat-file", "--batch-check=%(objectname) %(objecttype)")
+	if err != nil {
+		return nil, err
+	}
+	var commits []string
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		sha, kind, ok := strings.Cut(strings.TrimSpace(ln), " ")
+		if ok && kind == "commit" {
+			commits = append(commits, sha)
+		}
+	}
+	if len(commits) == 0 {
+		return out, nil
+	}
+	args := append([]string{"show", "-s", "--no-walk", "--format=%H%x00%ct%x00%s"}, commits...)
+	res, err = r.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		parts := strings.SplitN(ln, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		secs, _ := strconv.ParseInt(parts[1], 10, 64)
+		out[parts[0]] = Commit{SHA: parts[0], Time: time.Unix(secs, 0), Subject: parts[2]}
+	}
+	return out, nil
+}
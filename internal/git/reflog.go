@@ -0,0 +1,172 @@
+package git
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const checkoutPrefix = "checkout: moving from "
+
+// LastCheckouts returns, for every branch mentioned in the HEAD reflog, the
+// most recent time it was checked out. A branch counts as checked out both when
+// HEAD moved to it and when HEAD moved away from it, since it was the working
+// branch until that moment.
+// It runs: git log -g --date=unix --format=%gd%x09%gs HEAD
+// A repository without a HEAD reflog yields an empty map and nil error.
+func LastCheckouts(ctx context.Context, r Runner) (map[string]time.Time, error) {
+	res, err := r.Run(ctx, "log", "-g", "--date=unix", "--format=%gd%x09%gs", "HEAD")
+	if err != nil {
+		// Unborn HEAD or reflogs disabled; there is simply no history to use.
+		return map[string]time.Time{}, nil
+	}
+	return parseCheckoutReflog(res.Stdout), nil
+}
+
+func parseCheckoutReflog(output string) map[string]time.Time {
+	out := make(map[string]time.Time)
+	for _, ln := range strings.Split(output, "\n") {
+		selector, subject, ok := strings.Cut(ln, "\t")
+		if !ok {
+			continue
+		}
+		from, to, ok := CheckoutSubject(subject)
+		if !ok {
+			continue
+		}
+		when, ok := reflogSelectorTime(selector)
+		if !ok {
+			continue
+		}
+		for _, name := range []string{from, to} {
+			if name == "" || name == "HEAD" {
+				continue
+			}
+			if prev, seen := out[name]; !seen || when.After(prev) {
+				out[name] = when
+			}
+		}
+	}
+	return out
+}
+
+// reflogSelectorTime extracts the unix timestamp from a selector such as
+// "HEAD@{1697040000}" printed by --date=unix.
+func reflogSelectorTime(selector string) (time.Time, bool) {
+	open := strings.Index(selector, "@{")
+	if open < 0 || !strings.HasSuffix(selector, "}") {
+		return time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(selector[open+2:len(selector)-1], 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+// ReflogEntry is one entry of a reflog: the commit the ref moved to, when,
+// and the reflog message (e.g., "checkout: moving from a to b").
+type ReflogEntry struct {
+	Commit  string
+	Time    time.Time
+	Subject string
+}
+
+// HeadReflog returns the HEAD reflog, newest first.
+// It runs: git log -g --date=unix --format=%H%x00%gd%x00%gs HEAD
+// A repository without a HEAD reflog yields no entries and nil error.
+func HeadReflog(ctx context.Context, r Runner) ([]ReflogEntry, error) {
+	res, err := r.Run(ctx, "log", "-g", "--date=unix", "--format=%H%x00%gd%x00%gs", "HEAD")
+	if err != nil {
+		// Unborn HEAD or reflogs disabled, as in LastCheckouts.
+		return nil, nil
+	}
+	var entries []ReflogEntry
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		parts := strings.SplitN(ln, "\x00", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		when, _ := reflogSelectorTime(parts[1])
+		entries = append(entries, ReflogEntry{Commit: parts[0], Time: when, Subject: parts[2]})
+	}
+	return entries, nil
+}
+
+// CheckoutSubject splits a "checkout: moving from <from> to <to>" reflog
+// message; ok is false for any other message.
+func CheckoutSubject(subject string) (from, to string, ok bool) {
+	rest, ok := strings.CutPrefix(subject, checkoutPrefix)
+	if !ok {
+		return "", "", false
+	}
+	from, to, ok = strings.Cut(rest, " to ")
+	return strings.TrimSpace(from), strings.TrimSpace(to), ok
+}
+
+// OrphanedReflog is the last recorded value of a ref that no longer exists but
+// whose reflog file is still present.
+type OrphanedReflog struct {
+	Ref  string
+	Tip  string
+	Time time.Time
+}
+
+// OrphanedReflogs scans the reflog files under logs/<prefix> in the common git
+// directory (e.g., prefix "refs/remotes") and returns the last value of every
+// ref that no longer exists. Git usually removes a ref's reflog with the ref,
+// but reflogs kept by core.logAllRefUpdates=always or older tools survive.
+func OrphanedReflogs(ctx context.Context, r Runner, prefix string) ([]OrphanedReflog, error) {
+	dir, err := CommonDir(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := RefTips(ctx, r, prefix)
+	if err != nil {
+		return nil, err
+	}
+	logsDir := filepath.Join(dir, "logs")
+	var out []OrphanedReflog
+	walkErr := filepath.WalkDir(filepath.Join(logsDir, filepath.FromSlash(prefix)), func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(logsDir, path)
+		if err != nil {
+			return nil
+		}
+		ref := filepath.ToSlash(rel)
+		if _, ok := existing[ref]; ok {
+			return nil
+		}
+		if tip, when, ok := lastReflogValue(path); ok {
+			out = append(out, OrphanedReflog{Ref: ref, Tip: tip, Time: when})
+		}
+		return nil
+	})
+	return out, walkErr
+}
+
+// lastReflogValue reads the new value and time of the last line of a reflog
+// file ("<old> <new> <ident> <unix> <tz>\t<message>").
+func lastReflogValue(path string) (string, time.Time, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	head, _, _ := strings.Cut(lines[len(lines)-1], "\t")
+	fields := strings.Fields(head)
+	if len(fields) < 4 || strings.Trim(fields[1], "0") == "" {
+		return "", time.Time{}, false
+	}
+	secs, err := strconv.ParseInt(fields[len(fields)-2], 10, 64)
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return fields[1], time.Unix(secs, 0), true
+}
@@ -0,0 +1,28 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCheckoutReflog(t *testing.T) {
+	input := "" +
+		"HEAD@{1700000300}\tcheckout: moving from feature/a to main\n" +
+		"HEAD@{1700000200}\tcommit: work\n" +
+		"HEAD@{1700000100}\tcheckout: moving from main to feature/a\n" +
+		"HEAD@{1700000000}\tcheckout: moving from old to HEAD\n"
+
+	got := parseCheckoutReflog(input)
+	if !got["feature/a"].Equal(time.Unix(1700000300, 0)) {
+		t.Fatalf("feature/a: got %v", got["feature/a"])
+	}
+	if !got["main"].Equal(time.Unix(1700000300, 0)) {
+		t.Fatalf("main: got %v", got["main"])
+	}
+	if !got["old"].Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("old: got %v", got["old"])
+	}
+	if _, ok := got["HEAD"]; ok {
+		t.Fatalf("detached HEAD should not be recorded as a branch")
+	}
+}
@@ -0,0 +1,197 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RefDeletion names a ref to delete and the value it must still have.
+// When MoveTo is set, the ref is recreated under that name at OldTip in the
+// same transaction, which fails if MoveTo already exists.
+type RefDeletion struct {
+	Ref    string
+	OldTip string
+	MoveTo string
+}
+
+// DeleteRefs deletes (or moves) all refs in a single `git update-ref --stdin`
+// transaction. Each deletion verifies that the ref still points at OldTip, so
+// a ref that moved in the meantime makes the whole transaction fail and
+// nothing changes.
+// Deletions use no-deref, so a symbolic branch is removed itself and never the
+// branch it points to.
+// An empty OldTip is rejected rather than deleting unverified. Lock contention
+// with other git processes is retried with backoff.
+func DeleteRefs(ctx context.Context, r Runner, dels []RefDeletion) error {
+	if len(dels) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("start\n")
+	for _, d := range dels {
+		if d.OldTip == "" {
+			return fmt.Errorf("refusing to delete %s without a verified old value", d.Ref)
+		}
+		if d.MoveTo != "" {
+			fmt.Fprintf(&b, "create %s %s\n", d.MoveTo, d.OldTip)
+		}
+		fmt.Fprintf(&b, "option no-deref\ndelete %s %s\n", d.Ref, d.OldTip)
+	}
+	b.WriteString("prepare\ncommit\n")
+	return runRefTransaction(ctx, r, b.String())
+}
+
+// runRefTransaction feeds a transaction to `git update-ref --stdin`, retrying
+// on lock contention with other git processes.
+func runRefTransaction(ctx context.Context, r Runner, input string) error {
+	res, err := retryOnLock(ctx, func() (Result, error) {
+		return RunInput(ctx, r, input, "update-ref", "--stdin")
+	})
+	if err != nil {
+		// Only git's first line names the problem; the rest is generic advice.
+		if msg, _, _ := strings.Cut(strings.TrimSpace(res.Stderr), "\n"); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
+
+// RefTips returns the object each ref under prefix currently points to, keyed
+// by full refname.
+// It runs: git for-each-ref --format=%(refname)%00%(objectname) <prefix>
+func RefTips(ctx context.Context, r Runner, prefix string) (map[string]string, error) {
+	res, err := r.Run(ctx, "for-each-ref", "--format=%(refname)%00%(objectname)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	tips := make(map[string]string)
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		ref, tip, ok := strings.Cut(ln, "\x00")
+		if !ok {
+			continue
+		}
+		tips[ref] = tip
+	}
+	return tips, nil
+}
+
+// Ref is a ref with the commit date of what it points to; Date is the zero
+// time when the ref does not point at a commit.
+type Ref struct {
+	Name   string
+	Object string
+	Date   time.Time
+}
+
+// ListRefs returns the refs under prefix, unlike ListLocalBranches which is
+// limited to refs/heads.
+// It runs: git for-each-ref --format=%(refname)%00%(objectname)%00%(committerdate:unix) <prefix>
+func ListRefs(ctx context.Context, r Runner, prefix string) ([]Ref, error) {
+	res, err := r.Run(ctx, "for-each-ref", "--format=%(refname)%00%(objectname)%00%(committerdate:unix)", prefix)
+	if err != nil {
+		return nil, err
+	}
+	return parseRefList(res.Stdout), nil
+}
+
+func parseRefList(output string) []Ref {
+	var refs []Ref
+	for _, ln := range strings.Split(output, "\n") {
+		parts := strings.Split(ln, "\x00")
+		if len(parts) != 3 {
+			continue
+		}
+		ref := Ref{Name: parts[0], Object: parts[1]}
+		if sec, err := strconv.ParseInt(parts[2], 10, 64); err == nil {
+			ref.Date = time.Unix(sec, 0)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// WorktreeBranches returns the full refs of branches checked out in any
+// worktree of the repository, including the current one.
+// It runs: git worktree list --porcelain
+func WorktreeBranches(ctx context.Context, r Runner) ([]string, error) {
+	res, err := r.Run(ctx, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		if ref, ok := strings.CutPrefix(strings.TrimSpace(ln), "branch "); ok {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// RefCreation names a ref to create and the commit it should point to.
+type RefCreation struct {
+	Ref string
+	Tip string
+}
+
+// CreateRefs creates all refs in a single `git update-ref --stdin`
+// transaction; it fails without changes if any of them already exists.
+func CreateRefs(ctx context.Context, r Runner, creates []RefCreation) error {
+	if len(creates) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("start\n")
+	for _, c := range creates {
+		fmt.Fprintf(&b, "create %s %s\n", c.Ref, c.Tip)
+	}
+	b.WriteString("prepare\ncommit\n")
+	return runRefTransaction(ctx, r, b.String())
+}
+
+// RefUpdate moves Ref from OldTip to NewTip.
+type RefUpdate struct {
+	Ref    string
+	OldTip string
+	NewTip string
+}
+
+// UpdateRefs applies all updates in a single `git update-ref --stdin`
+// transaction; it fails without changes if any ref no longer points at its
+// OldTip.
+func UpdateRefs(ctx context.Context, r Runner, updates []RefUpdate) error {
+	if len(updates) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString("start\n")
+	for _, u := range updates {
+		if u.OldTip == "" {
+			return fmt.Errorf("refusing to update %s without a verified old value", u.Ref)
+		}
+		fmt.Fprintf(&b, "update %s %s %s\n", u.Ref, u.NewTip, u.OldTip)
+	}
+	b.WriteString("prepare\ncommit\n")
+	return runRefTransaction(ctx, r, b.String())
+}
+
+// RefsContaining returns the refs under the prefixes whose history contains
+// commit.
+// It runs: git for-each-ref --contains <commit> --format=%(refname) <prefix>...
+func RefsContaining(ctx context.Context, r Runner, commit string, prefixes ...string) ([]string, error) {
+	args := append([]string{"for-each-ref", "--contains", commit, "--format=%(refname)"}, prefixes...)
+	res, err := r.Run(ctx, args...)
+	if err != nil {
+		return nil, err
+	}
+	var refs []string
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		if ref := strings.TrimSpace(ln); ref != "" {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
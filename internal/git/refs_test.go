@@ -0,0 +1,23 @@
+package git
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRefList(t *testing.T) {
+	input := "" +
+		"refs/pull/1/head\x00aaa\x001700000000\n" +
+		"refs/pull/2/head\x00bbb\x00\n"
+
+	refs := parseRefList(input)
+	if len(refs) != 2 {
+		t.Fatalf("expected 2 refs, got %d", len(refs))
+	}
+	if refs[0].Name != "refs/pull/1/head" || refs[0].Object != "aaa" || !refs[0].Date.Equal(time.Unix(1700000000, 0)) {
+		t.Fatalf("unexpected first ref: %+v", refs[0])
+	}
+	if !refs[1].Date.IsZero() {
+		t.Fatalf("expected no date for a non-commit, got %v", refs[1].Date)
+	}
+}
@@ -2,6 +2,8 @@
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +25,22 @@ func RemoteDefaultRef(ctx context.Context, r Runner, remote string) (string, err
 	return strings.TrimPrefix(full, prefix), nil // e.g., origin/main
 }
 
+// Remotes returns the names of the configured remotes.
+// It runs: git remote
+func Remotes(ctx context.Context, r Runner) ([]string, error) {
+	res, err := r.Run(ctx, "remote")
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		if name := strings.TrimSpace(ln); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
 // IsAncestor reports whether commit-ish a is an ancestor of commit-ish b.
 // It runs: git merge-base --is-ancestor a b
 func IsAncestor(ctx context.Context, r Runner, a, b string) (bool, error) {
@@ -33,3 +51,118 @@ func IsAncestor(ctx context.Context, r Runner, a, b string) (bool, error) {
 	}
 	return true, nil
 }
+
+// HasMergeBase reports whether commit-ishes a and b share any history.
+// It runs: git merge-base a b, which exits 1 when there is no merge base.
+func HasMergeBase(ctx context.Context, r Runner, a, b string) (bool, error) {
+	res, err := r.Run(ctx, "merge-base", a, b)
+	if err != nil {
+		if res.ExitCode == 1 && strings.TrimSpace(res.Stderr) == "" {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// UnpushedCount returns the number of commits reachable from ref that are not
+// reachable from any remote-tracking ref.
+// It runs: git rev-list --count <ref> --not --remotes
+func UnpushedCount(ctx context.Context, r Runner, ref string) (int, error) {
+	res, err := r.Run(ctx, "rev-list", "--count", ref, "--not", "--remotes")
+	if err != nil {
+		return 0, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(res.Stdout))
+	if err != nil {
+		return 0, fmt.Errorf("unexpected output for rev-list --count: %q", res.Stdout)
+	}
+	return n, nil
+}
+
+// PushRefs pushes refspecs such as "<commit>:refs/heads/x" to remote without
+// forcing, and returns the destination refs the remote accepted (including
+// ones already up to date). When some refs are rejected the accepted ones are
+// still returned along with the error.
+// It runs: git push --porcelain <remote> <refspecs>...
+func PushRefs(ctx context.Context, r Runner, remote string, refspecs []string) (map[string]bool, error) {
+	accepted := make(map[string]bool)
+	if len(refspecs) == 0 {
+		return accepted, nil
+	}
+	res, err := r.Run(ctx, append([]string{"push", "--porcelain", remote}, refspecs...)...)
+	for dst := range parsePushPorcelain(res.Stdout) {
+		accepted[dst] = true
+	}
+	return accepted, err
+}
+
+// parsePushPorcelain returns the destination refs of successful lines in
+// `git push --porcelain` output ("<flag>\t<src>:<dst>\t<summary>"), where a
+// flag of '!' marks a rejected ref.
+func parsePushPorcelain(output string) map[string]bool {
+	ok := make(map[string]bool)
+	for _, ln := range strings.Split(output, "\n") {
+		fields := strings.Split(ln, "\t")
+		if len(fields) < 3 || len(fields[0]) != 1 || fields[0] == "!" {
+			continue
+		}
+		if _, dst, found := strings.Cut(fields[1], ":"); found {
+			ok[dst] = true
+		}
+	}
+	return ok
+}
+
+// RemoteHead asks the remote which branch its HEAD points to, e.g. "main".
+// It returns an empty string when the remote does not report one.
+// It runs: git ls-remote --symref <remote> HEAD
+func RemoteHead(ctx context.Context, r Runner, remote string) (string, error) {
+	res, err := r.Run(ctx, "ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	for _, ln := range strings.Split(res.Stdout, "\n") {
+		rest, ok := strings.CutPrefix(ln, "ref: ")
+		if !ok {
+			continue
+		}
+		if target, name, ok := strings.Cut(rest, "\t"); ok && name == "HEAD" {
+			return strings.TrimPrefix(target, headsPrefix), nil
+		}
+	}
+	return "", nil
+}
+
+// SetRemoteHead points refs/remotes/<remote>/HEAD at <remote>/<branch>
+// without contacting the remote.
+// It runs: git remote set-head <remote> <branch>
+func SetRemoteHead(ctx context.Context, r Runner, remote, branch string) error {
+	_, err := r.Run(ctx, "remote", "set-head", remote, branch)
+	return err
+}
+
+// RemoteRefs asks the remote for its refs under prefix (e.g., "refs/pull/")
+// and returns their full refnames.
+// It runs: git ls-remote --refs <remote> <prefix>*
+func RemoteRefs(ctx context.Context, r Runner, remote, prefix string) (map[string]bool, error) {
+	res, err := r.Run(ctx, "ls-remote", "--refs", remote, prefix+"*")
+	if err != nil {
+		return nil, err
+	}
+	return parseLsRemote(res.Stdout, prefix), nil
+}
+
+// parseLsRemote parses `<object>\t<ref>` lines into a set of the refs under
+// prefix, skipping peeled entries.
+func parseLsRemote(output, prefix string) map[string]bool {
+	refs := make(map[string]bool)
+	for _, ln := range strings.Split(output, "\n") {
+		_, ref, ok := strings.Cut(ln, "\t")
+		if !ok || !strings.HasPrefix(ref, prefix) || strings.HasSuffix(ref, "^{}") {
+			continue
+		}
+		refs[ref] = true
+	}
+	return refs
+}
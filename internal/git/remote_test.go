@@ -0,0 +1,19 @@
+package git
+
+import "testing"
+
+func TestParsePushPorcelain(t *testing.T) {
+	output := "To ../rescue.git\n" +
+		"*\t0123abc:refs/heads/rescue/jane/a\t[new branch]\n" +
+		"=\t4567def:refs/heads/rescue/jane/b\t[up to date]\n" +
+		"!\t89abcde:refs/heads/rescue/jane/c\t[rejected] (non-fast-forward)\n" +
+		"Done"
+
+	got := parsePushPorcelain(output)
+	if !got["refs/heads/rescue/jane/a"] || !got["refs/heads/rescue/jane/b"] {
+		t.Fatalf("accepted refs missing: %v", got)
+	}
+	if got["refs/heads/rescue/jane/c"] || len(got) != 2 {
+		t.Fatalf("rejected ref reported as accepted: %v", got)
+	}
+}
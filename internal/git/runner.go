@@ -2,6 +2,7 @@
 
 import (
 	"context"
+	"errors"
 )
 
 // Result contains the outputs and exit code of a git command execution.
@@ -40,3 +41,23 @@ type Result struct {
 type Runner interface {
 	Run(ctx context.Context, args ...string) (Result, error)
 }
+
+// InputRunner is implemented by runners that can feed data to git's standard
+// input, as needed by commands such as `git update-ref --stdin`. It is kept
+// separate from Runner so simple test doubles need not implement it.
+type InputRunner interface {
+	RunInput(ctx context.Context, input string, args ...string) (Result, error)
+}
+
+// ErrNoInputSupport is returned when a command needs standard input but the
+// Runner does not implement InputRunner.
+var ErrNoInputSupport = errors.New("git runner does not support standard input")
+
+// RunInput runs git with input on standard input when r supports it.
+func RunInput(ctx context.Context, r Runner, input string, args ...string) (Result, error) {
+	ir, ok := r.(InputRunner)
+	if !ok {
+		return Result{}, ErrNoInputSupport
+	}
+	return ir.RunInput(ctx, input, args...)
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// Stash describes one entry of refs/stash.
+// Ref is the reflog selector (e.g., "stash@{0}"), Base is the commit the stash
+// was taken on (its first parent) and Branch is the branch named in the stash
+// subject, empty when the stash was made on a detached HEAD.
+type Stash struct {
+	Ref    string
+	Base   string
+	Branch string
+}
+
+// ListStashes returns the entries of refs/stash, newest first.
+// It runs: git stash list --format=%gd%x09%P%x09%gs
+func ListStashes(ctx context.Context, r Runner) ([]Stash, error) {
+	res, err := r.Run(ctx, "stash", "list", "--format=%gd%x09%P%x09%gs")
+	if err != nil {
+		return nil, err
+	}
+	return parseStashList(res.Stdout), nil
+}
+
+func parseStashList(output string) []Stash {
+	var stashes []Stash
+	for _, ln := range strings.Split(output, "\n") {
+		parts := strings.SplitN(ln, "\t", 3)
+		if len(parts) < 3 {
+			continue
+		}
+		base, _, _ := strings.Cut(strings.TrimSpace(parts[1]), " ")
+		stashes = append(stashes, Stash{
+			Ref:    strings.TrimSpace(parts[0]),
+			Base:   base,
+			Branch: stashSubjectBranch(parts[2]),
+		})
+	}
+	return stashes
+}
+
+// stashSubjectBranch extracts the branch from "WIP on <branch>: ..." or
+// "On <branch>: ..." subjects.
+func stashSubjectBranch(subject string) string {
+	rest, ok := strings.CutPrefix(subject, "WIP on ")
+	if !ok {
+		rest, ok = strings.CutPrefix(subject, "On ")
+	}
+	if !ok {
+		return ""
+	}
+	name, _, ok := strings.Cut(rest, ": ")
+	if !ok || name == "(no branch)" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,25 @@
+package git
+
+import "testing"
+
+func TestParseStashList(t *testing.T) {
+	input := "" +
+		"stash@{0}\taaa111 bbb222\tWIP on (no branch): aaa111 msg\n" +
+		"stash@{1}\tccc333 ddd444\tOn feature/x: named\n" +
+		"stash@{2}\teee555 fff666 ggg777\tWIP on main: eee555 msg\n"
+
+	stashes := parseStashList(input)
+	if len(stashes) != 3 {
+		t.Fatalf("expected 3 stashes, got %d", len(stashes))
+	}
+	want := []Stash{
+		{Ref: "stash@{0}", Base: "aaa111", Branch: ""},
+		{Ref: "stash@{1}", Base: "ccc333", Branch: "feature/x"},
+		{Ref: "stash@{2}", Base: "eee555", Branch: "main"},
+	}
+	for i := range want {
+		if stashes[i] != want[i] {
+			t.Fatalf("stash %d: got %+v want %+v", i, stashes[i], want[i])
+		}
+	}
+}
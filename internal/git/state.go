@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Operation describes a multi-step git command that is still in progress.
+// Name is one of "rebase", "am", "merge", "cherry-pick", "revert" or "bisect";
+// it is empty when nothing is in progress. Branch is the short name of the
+// branch the operation started from, when git records it.
+type Operation struct {
+	Name   string
+	Branch string
+}
+
+// GitDir returns the absolute path of the repository's git directory.
+// It runs: git rev-parse --absolute-git-dir
+func GitDir(ctx context.Context, r Runner) (string, error) {
+	res, err := r.Run(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees, where repository-wide state such as refs and config lives.
+// It runs: git rev-parse --path-format=absolute --git-common-dir
+func CommonDir(ctx context.Context, r Runner) (string, error) {
+	res, err := r.Run(ctx, "rev-parse", "--path-format=absolute", "--git-common-dir")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(res.Stdout), nil
+}
+
+// InProgressOperation inspects the git directory for the state files left by
+// an unfinished rebase, am, merge, cherry-pick, revert or bisect.
+func InProgressOperation(ctx context.Context, r Runner) (Operation, error) {
+	dir, err := GitDir(ctx, r)
+	if err != nil {
+		return Operation{}, err
+	}
+	return operationInGitDir(dir), nil
+}
+
+// WorktreeOperationBranches returns the branches that an unfinished rebase, am
+// or bisect started from in any worktree, including the current one. Such a
+// branch shows as detached in `git worktree list`, yet the operation will move
+// it when it finishes.
+func WorktreeOperationBranches(ctx context.Context, r Runner) ([]string, error) {
+	common, err := CommonDir(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	dirs := []string{common}
+	linked, err := os.ReadDir(filepath.Join(common, "worktrees"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, e := range linked {
+		if e.IsDir() {
+			dirs = append(dirs, filepath.Join(common, "worktrees", e.Name()))
+		}
+	}
+	var branches []string
+	for _, dir := range dirs {
+		if op := operationInGitDir(dir); op.Branch != "" {
+			branches = append(branches, op.Branch)
+		}
+	}
+	return branches, nil
+}
+
+func operationInGitDir(dir string) Operation {
+	for _, sub := range []string{"rebase-merge", "rebase-apply"} {
+		if !exists(filepath.Join(dir, sub)) {
+			continue
+		}
+		name := "rebase"
+		if sub == "rebase-apply" && exists(filepath.Join(dir, sub, "applying")) {
+			name = "am"
+		}
+		op := Operation{Name: name}
+		if head := readTrimmed(filepath.Join(dir, sub, "head-name")); strings.HasPrefix(head, "refs/heads/") {
+			op.Branch = strings.TrimPrefix(head, "refs/heads/")
+		}
+		return op
+	}
+	for _, op := range []struct{ file, name string }{
+		{"MERGE_HEAD", "merge"},
+		{"CHERRY_PICK_HEAD", "cherry-pick"},
+		{"REVERT_HEAD", "revert"},
+	} {
+		if exists(filepath.Join(dir, op.file)) {
+			return Operation{Name: op.name}
+		}
+	}
+	if exists(filepath.Join(dir, "BISECT_LOG")) {
+		return Operation{Name: "bisect", Branch: readTrimmed(filepath.Join(dir, "BISECT_START"))}
+	}
+	return Operation{}
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// readTrimmed returns the trimmed file contents, or "" when it cannot be read.
+func readTrimmed(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,38 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOperationInGitDir(t *testing.T) {
+	cases := []struct {
+		name  string
+		files map[string]string
+		want  Operation
+	}{
+		{"clean", nil, Operation{}},
+		{"interactive rebase", map[string]string{"rebase-merge/head-name": "refs/heads/feature/x\n"}, Operation{Name: "rebase", Branch: "feature/x"}},
+		{"detached rebase", map[string]string{"rebase-merge/head-name": "detached HEAD\n"}, Operation{Name: "rebase"}},
+		{"am", map[string]string{"rebase-apply/applying": "", "rebase-apply/head-name": "refs/heads/main\n"}, Operation{Name: "am", Branch: "main"}},
+		{"merge", map[string]string{"MERGE_HEAD": "abc\n"}, Operation{Name: "merge"}},
+		{"cherry-pick", map[string]string{"CHERRY_PICK_HEAD": "abc\n"}, Operation{Name: "cherry-pick"}},
+		{"bisect", map[string]string{"BISECT_LOG": "", "BISECT_START": "topic\n"}, Operation{Name: "bisect", Branch: "topic"}},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		for name, data := range c.files {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+		}
+		if got := operationInGitDir(dir); got != c.want {
+			t.Errorf("%s: got %+v want %+v", c.name, got, c.want)
+		}
+	}
+}
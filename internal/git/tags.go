@@ -0,0 +1,17 @@
+package git
+
+import "context"
+
+// tagsPrefix is the namespace of tag refs.
+const tagsPrefix = "refs/tags/"
+
+// RemoteTags asks the remote for its tags and returns their full refnames.
+// Peeled entries ("<tag>^{}") are omitted.
+// It runs: git ls-remote --tags --refs <remote>
+func RemoteTags(ctx context.Context, r Runner, remote string) (map[string]bool, error) {
+	res, err := r.Run(ctx, "ls-remote", "--tags", "--refs", remote)
+	if err != nil {
+		return nil, err
+	}
+	return parseLsRemote(res.Stdout, tagsPrefix), nil
+}
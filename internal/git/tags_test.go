@@ -0,0 +1,20 @@
+package git
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLsRemote(t *testing.T) {
+	input := "" +
+		"1111\trefs/tags/v1.0\n" +
+		"2222\trefs/tags/v1.0^{}\n" +
+		"3333\trefs/tags/release/2.0\n" +
+		"4444\trefs/heads/main\n"
+
+	got := parseLsRemote(input, tagsPrefix)
+	want := map[string]bool{"refs/tags/v1.0": true, "refs/tags/release/2.0": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,33 @@
+package sweep
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseAge parses ages such as "90d" or "12w", falling back to Go durations
+// ("36h"). Ages must be positive: a zero or negative age would make every
+// entry old enough to expire.
+func ParseAge(s string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil || v <= 0 {
+				return 0, fmt.Errorf("invalid age %q", s)
+			}
+			return time.Duration(v) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, errors.New("invalid age " + strconv.Quote(s) + " (use e.g. 90d, 12w or 36h)")
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid age %q: must be positive", s)
+	}
+	return d, nil
+}
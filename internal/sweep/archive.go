@@ -0,0 +1,194 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// ArchiveMode selects what happens to swept branches instead of deletion.
+type ArchiveMode string
+
+const (
+	// ArchiveNone deletes branches outright.
+	ArchiveNone ArchiveMode = ""
+	// ArchiveRefs moves branches to refs/sweep-archive/<date>/<name>.
+	ArchiveRefs ArchiveMode = "ref"
+	// ArchiveTags moves branches to lightweight tags named archive/<name>.
+	ArchiveTags ArchiveMode = "tag"
+)
+
+const (
+	// ArchiveRefPrefix is the namespace of dated archive refs.
+	ArchiveRefPrefix = "refs/sweep-archive/"
+	// ArchiveTagPrefix is the namespace of archive tags.
+	ArchiveTagPrefix = "refs/tags/archive/"
+
+	archiveDateLayout = "2006-01-02"
+)
+
+// ErrNotArchived is returned by RestoreArchived when no archive entry matches.
+var ErrNotArchived = errors.New("no archived branch with that name")
+
+// ArchivedBranch is one branch kept in the archive namespaces.
+// Date is the day it was archived; it is zero for archive tags, whose names
+// carry no date.
+type ArchivedBranch struct {
+	Ref  string
+	Name string
+	Tip  string
+	Date time.Time
+}
+
+// ParseArchiveMode validates the --archive value.
+func ParseArchiveMode(s string) (ArchiveMode, error) {
+	switch ArchiveMode(s) {
+	case ArchiveNone, ArchiveRefs, ArchiveTags:
+		return ArchiveMode(s), nil
+	default:
+		return ArchiveNone, fmt.Errorf("invalid archive mode %q (want ref or tag)", s)
+	}
+}
+
+// archiveRef returns where mode keeps branch name when archived on day now.
+func archiveRef(mode ArchiveMode, name string, now time.Time) string {
+	switch mode {
+	case ArchiveRefs:
+		return ArchiveRefPrefix + now.Format(archiveDateLayout) + "/" + name
+	case ArchiveTags:
+		return ArchiveTagPrefix + name
+	default:
+		return ""
+	}
+}
+
+// ListArchive returns the archived branches from both namespaces, newest
+// dated entries first and tags last.
+func ListArchive(ctx context.Context, r git.Runner) ([]ArchivedBranch, error) {
+	var out []ArchivedBranch
+	for _, prefix := range []string{ArchiveRefPrefix, ArchiveTagPrefix} {
+		tips, err := git.RefTips(ctx, r, strings.TrimSuffix(prefix, "/"))
+		if err != nil {
+			return nil, err
+		}
+		for ref, tip := range tips {
+			if a, ok := parseArchiveRef(ref); ok {
+				a.Tip = tip
+				out = append(out, a)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Date.Equal(out[j].Date) {
+			return out[i].Date.After(out[j].Date)
+		}
+		return out[i].Ref < out[j].Ref
+	})
+	return out, nil
+}
+
+func parseArchiveRef(ref string) (ArchivedBranch, bool) {
+	if name, ok := strings.CutPrefix(ref, ArchiveTagPrefix); ok && name != "" {
+		return ArchivedBranch{Ref: ref, Name: name}, true
+	}
+	rest, ok := strings.CutPrefix(ref, ArchiveRefPrefix)
+	if !ok {
+		return ArchivedBranch{}, false
+	}
+	day, name, ok := strings.Cut(rest, "/")
+	if !ok || name == "" {
+		return ArchivedBranch{}, false
+	}
+	date, err := time.ParseInLocation(archiveDateLayout, day, time.Local)
+	if err != nil {
+		return ArchivedBranch{}, false
+	}
+	return ArchivedBranch{Ref: ref, Name: name, Date: date}, true
+}
+
+// RestoreArchived recreates branch name from its most recent archive entry and
+// removes that entry, in one transaction. It fails if the branch exists.
+// The branch.<name> config kept when archiving (its upstream, for instance)
+// applies again once the branch exists.
+func RestoreArchived(ctx context.Context, r git.Runner, name string) (ArchivedBranch, error) {
+	entries, err := ListArchive(ctx, r)
+	if err != nil {
+		return ArchivedBranch{}, err
+	}
+	for _, a := range entries {
+		if a.Name != name {
+			continue
+		}
+		err := git.DeleteRefs(ctx, r, []git.RefDeletion{{Ref: a.Ref, OldTip: a.Tip, MoveTo: "refs/heads/" + name}})
+		return a, err
+	}
+	return ArchivedBranch{}, fmt.Errorf("%w: %s", ErrNotArchived, name)
+}
+
+// ExpireArchive deletes dated archive refs older than olderThan relative to
+// now and returns them. Archive tags are left for `git tag -d`. The
+// branch.<name> config kept for an expired branch is removed once no archive
+// entry or branch of that name is left.
+func ExpireArchive(ctx context.Context, r git.Runner, olderThan time.Duration, now time.Time) ([]ArchivedBranch, error) {
+	entries, err := ListArchive(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	cutoff := now.Add(-olderThan)
+	var expired []ArchivedBranch
+	var dels []git.RefDeletion
+	for _, a := range entries {
+		if a.Date.IsZero() || !a.Date.Before(cutoff) {
+			continue
+		}
+		expired = append(expired, a)
+		dels = append(dels, git.RefDeletion{Ref: a.Ref, OldTip: a.Tip})
+	}
+	if err := git.DeleteRefs(ctx, r, dels); err != nil {
+		return nil, err
+	}
+	removeExpiredConfigs(ctx, r, expired)
+	return expired, nil
+}
+
+// removeExpiredConfigs drops the branch.<name> config of expired archive
+// entries whose name has no remaining archive entry or branch. It is best
+// effort, like removeBranchConfigs.
+func removeExpiredConfigs(ctx context.Context, r git.Runner, expired []ArchivedBranch) {
+	if len(expired) == 0 {
+		return
+	}
+	remaining, err := archivedNames(ctx, r)
+	if err != nil {
+		return
+	}
+	heads, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return
+	}
+	var names []string
+	for _, a := range expired {
+		if _, exists := heads["refs/heads/"+a.Name]; !exists && !remaining[a.Name] {
+			names = append(names, a.Name)
+		}
+	}
+	removeBranchConfigs(ctx, r, names)
+}
+
+// archivedNames returns the branch names with at least one archive entry.
+func archivedNames(ctx context.Context, r git.Runner) (map[string]bool, error) {
+	entries, err := ListArchive(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(entries))
+	for _, a := range entries {
+		names[a.Name] = true
+	}
+	return names, nil
+}
@@ -0,0 +1,32 @@
+package sweep
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseArchiveRef(t *testing.T) {
+	a, ok := parseArchiveRef("refs/sweep-archive/2026-01-31/feature/x")
+	if !ok || a.Name != "feature/x" || a.Date.Format("2006-01-02") != "2026-01-31" {
+		t.Fatalf("unexpected dated entry: %+v ok=%v", a, ok)
+	}
+	a, ok = parseArchiveRef("refs/tags/archive/feature/y")
+	if !ok || a.Name != "feature/y" || !a.Date.IsZero() {
+		t.Fatalf("unexpected tag entry: %+v ok=%v", a, ok)
+	}
+	for _, bad := range []string{"refs/sweep-archive/not-a-date/x", "refs/sweep-archive/2026-01-31", "refs/heads/x"} {
+		if _, ok := parseArchiveRef(bad); ok {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestArchiveRef(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	if got := archiveRef(ArchiveRefs, "feature/x", now); got != "refs/sweep-archive/2026-01-31/feature/x" {
+		t.Fatalf("unexpected ref: %s", got)
+	}
+	if got := archiveRef(ArchiveTags, "feature/x", now); got != "refs/tags/archive/feature/x" {
+		t.Fatalf("unexpected tag: %s", got)
+	}
+}
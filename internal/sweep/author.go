@@ -0,0 +1,69 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"regexp"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// authorMatchers compiles the author constraints from Options. Mine matches the
+// configured user.email (case-insensitively); AuthorPattern is a regex applied
+// to "Name <email>" like `git log --author`. Both may be combined.
+func authorMatchers(ctx context.Context, r git.Runner, opts Options) ([]*regexp.Regexp, error) {
+	var out []*regexp.Regexp
+	if opts.Mine {
+		email, err := git.ConfigValue(ctx, r, "user.email")
+		if err != nil {
+			return nil, err
+		}
+		if email == "" {
+			return nil, errors.New("--mine requires user.email to be configured")
+		}
+		out = append(out, regexp.MustCompile("(?i)<"+regexp.QuoteMeta(email)+">"))
+	}
+	if opts.AuthorPattern != "" {
+		re, err := regexp.Compile(opts.AuthorPattern)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+// filterByAuthor keeps only branches whose unique commits were all authored by
+// identities matching every matcher.
+func filterByAuthor(ctx context.Context, r git.Runner, branches []git.Branch, matchers []*regexp.Regexp) ([]git.Branch, error) {
+	if len(matchers) == 0 {
+		return branches, nil
+	}
+	var kept []git.Branch
+	for _, b := range branches {
+		authors, err := git.UniqueAuthors(ctx, r, b.Name)
+		if err != nil {
+			return nil, err
+		}
+		if authoredBy(authors, matchers) {
+			kept = append(kept, b)
+		}
+	}
+	return kept, nil
+}
+
+// authoredBy reports whether every author matches all matchers. A branch with
+// no known authors is never considered owned.
+func authoredBy(authors []string, matchers []*regexp.Regexp) bool {
+	if len(authors) == 0 {
+		return false
+	}
+	for _, a := range authors {
+		for _, re := range matchers {
+			if !re.MatchString(a) {
+				return false
+			}
+		}
+	}
+	return true
+}
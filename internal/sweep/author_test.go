@@ -0,0 +1,40 @@
+package sweep
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// scriptRunner returns canned stdout keyed by the space-joined git arguments.
+type scriptRunner map[string]string
+
+func (s scriptRunner) Run(_ context.Context, args ...string) (git.Result, error) {
+	return git.Result{Stdout: s[strings.Join(args, " ")]}, nil
+}
+
+func TestFilterByAuthor(t *testing.T) {
+	unique := func(b string) string {
+		return "log --format=%an <%ae> refs/heads/" + b + " --not --exclude=" + b + " --branches --remotes"
+	}
+	r := scriptRunner{
+		unique("mine"):   "Me <me@example.com>\nMe <ME@example.com>",
+		unique("theirs"): "Other <other@example.com>",
+		unique("paired"): "Me <me@example.com>\nOther <other@example.com>",
+		unique("merged"): "",
+		"log -1 --format=%an <%ae> refs/heads/merged": "Me <me@example.com>",
+	}
+	branches := []git.Branch{{Name: "mine"}, {Name: "theirs"}, {Name: "paired"}, {Name: "merged"}}
+	matchers := []*regexp.Regexp{regexp.MustCompile("(?i)<me@example\\.com>")}
+
+	kept, err := filterByAuthor(context.Background(), r, branches, matchers)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(kept) != 2 || kept[0].Name != "mine" || kept[1].Name != "merged" {
+		t.Fatalf("unexpected selection: %+v", kept)
+	}
+}
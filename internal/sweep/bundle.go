@@ -0,0 +1,74 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// writeBundle exports branches into a bundle at path before deletion and
+// returns the names of the branches it holds, which is all of them.
+func writeBundle(ctx context.Context, r git.Runner, path string, branches []git.Branch) ([]string, error) {
+	refs := make([]git.RefCreation, 0, len(branches))
+	names := make([]string, 0, len(branches))
+	for _, b := range branches {
+		refs = append(refs, git.RefCreation{Ref: b.Ref, Tip: b.Tip})
+		names = append(names, b.Name)
+	}
+	if err := git.CreateBundle(ctx, r, path, refs); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// RestoreFromBundle recreates branches from a bundle written by --bundle.
+// With no names every branch in the bundle is restored. Branches that already
+// exist are skipped; upstream config is not part of a bundle.
+func RestoreFromBundle(ctx context.Context, r git.Runner, path string, names []string) (RestoreResult, error) {
+	var res RestoreResult
+	if err := git.VerifyBundle(ctx, r, path); err != nil {
+		return res, err
+	}
+	heads, err := git.BundleHeads(ctx, r, path)
+	if err != nil {
+		return res, err
+	}
+	byName := make(map[string]git.RefCreation, len(heads))
+	for _, h := range heads {
+		if name, ok := strings.CutPrefix(h.Ref, "refs/heads/"); ok && name != "" {
+			byName[name] = h
+		}
+	}
+	if len(names) == 0 {
+		for name := range byName {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	existing, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return res, err
+	}
+	var refspecs []string
+	for _, name := range names {
+		h, ok := byName[name]
+		if !ok {
+			return RestoreResult{}, fmt.Errorf("branch %s is not in bundle %s", name, path)
+		}
+		b := JournalBranch{Name: name, Ref: h.Ref, Tip: h.Tip}
+		if _, ok := existing[h.Ref]; ok {
+			res.Skipped = append(res.Skipped, b)
+			continue
+		}
+		refspecs = append(refspecs, h.Ref+":"+h.Ref)
+		res.Restored = append(res.Restored, b)
+	}
+	if err := git.FetchRefs(ctx, r, path, refspecs); err != nil {
+		return RestoreResult{}, err
+	}
+	return res, nil
+}
@@ -0,0 +1,47 @@
+package sweep
+
+import (
+	"context"
+	"sort"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// orphanedBranchConfigs returns the names of the `branch.<name>` sections of
+// cfg whose branch no longer exists, e.g. after `git update-ref -d` or another tool
+// removed the ref, sorted by name. Existence is checked against refs/heads
+// directly so branches without an upstream are never mistaken for missing.
+// Sections of archived branches are kept for `archive restore`.
+func orphanedBranchConfigs(ctx context.Context, r git.Runner, cfg map[string][]git.ConfigEntry) ([]string, error) {
+	if len(cfg) == 0 {
+		return nil, nil
+	}
+	heads, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	archived, err := archivedNames(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var orphaned []string
+	for name := range cfg {
+		if _, ok := heads["refs/heads/"+name]; !ok && !archived[name] {
+			orphaned = append(orphaned, name)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned, nil
+}
+
+// PruneBranchConfigs removes the `branch.<name>` config sections listed in
+// Plan.OrphanedConfigs and returns the failures keyed by branch name.
+func PruneBranchConfigs(ctx context.Context, r git.Runner, names []string) map[string]error {
+	failed := make(map[string]error)
+	for _, name := range names {
+		if err := git.RemoveBranchConfig(ctx, r, name); err != nil {
+			failed[name] = err
+		}
+	}
+	return failed
+}
@@ -0,0 +1,28 @@
+package sweep
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestOrphanedBranchConfigs(t *testing.T) {
+	cfg := map[string][]git.ConfigEntry{
+		"main": {{Key: "remote", Value: "origin"}},
+		"gone": {{Key: "remote", Value: "origin"}},
+		"v1.2": {{Key: "description", Value: "old"}},
+	}
+	r := &policyRunner{out: map[string]string{
+		"for-each-ref --format=%(refname)%00%(objectname) refs/heads": "refs/heads/main\x00abc\n",
+	}}
+
+	got, err := orphanedBranchConfigs(context.Background(), r, cfg)
+	if err != nil {
+		t.Fatalf("orphanedBranchConfigs: %v", err)
+	}
+	if want := []string{"gone", "v1.2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -5,47 +5,164 @@
 	"errors"
 	"fmt"
 	"runtime"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/jmelosegui/git-sweep/internal/git"
 )
 
 // ExecuteOptions controls how deletion is performed.
+// Atomic makes the sweep all-or-nothing: if any candidate is refused or has
+// moved since planning, no branch is deleted.
+// Archive moves branches into an archive namespace instead of deleting them;
+// Now dates archive refs and journal runs and defaults to time.Now.
+// Journal records the approved branches, their config and CommandLine in the
+// deletion journal before anything is deleted (see Undo).
+// BundlePath, when set, exports the approved branches and the history they
+// do not share into a git bundle before anything is deleted.
+// RescueRemote, when set, pushes branches whose upstream is gone and that have
+// unpushed commits to rescue/<user>/<branch> on that remote first; such
+// branches no longer need ForceDelete, and a branch whose push fails is kept.
+// Other refused candidates, such as stale branches, are not rescued.
+// RepointDependents sets the upstream of branches stacked on a deleted branch
+// (see Plan.Dependents) to the remote default branch.
 type ExecuteOptions struct {
-	MaxParallel int
-	ForceDelete bool // when true, use `git branch -D` instead of `-d`
+	MaxParallel       int
+	ForceDelete       bool // when true, delete every candidate without merge/push proofs
+	Atomic            bool
+	Archive           ArchiveMode
+	Now               time.Time
+	Journal           bool
+	CommandLine       []string
+	BundlePath        string
+	RescueRemote      string
+	RepointDependents bool
 }
 
 // Result holds per-branch deletion outcomes.
+// Methods records how each deleted branch was proven safe to delete, and Tips
+// the commit it pointed to.
+// Archived maps each archived branch to the ref now holding its tip.
+// Bundled lists the branches written to ExecuteOptions.BundlePath.
+// Rescued maps each rescued branch to its rescue branch, e.g.
+// "origin/rescue/jane/feature/x".
+// SwitchedTo is the branch checked out for Plan.SwitchTo; FastForwardErr is
+// set when it could not be fast-forwarded to the remote default branch.
+// DefaultRenamed is the applied Plan.DefaultRename.
+// Repointed maps stacked branches re-pointed by RepointDependents to their new
+// upstream.
 type Result struct {
-	Deleted []string
-	Failed  map[string]error
+	Deleted        []string
+	Methods        map[string]DeleteMethod
+	Tips           map[string]string
+	Archived       map[string]string
+	Rescued        map[string]string
+	Bundled        []string
+	Failed         map[string]error
+	SwitchedTo     string
+	FastForwardErr error
+	DefaultRenamed *DefaultRename
+	Repointed      map[string]string
 }
 
+// ErrOperationInProgress is returned by ExecuteDeletions when the plan was built
+// while a rebase, merge, cherry-pick, revert or bisect was in progress.
+var ErrOperationInProgress = errors.New("git operation in progress")
+
+var (
+	errMoved         = errors.New("branch moved since planning; left alone")
+	errUnknownTip    = errors.New("tip unknown at planning time; cannot verify")
+	errAtomicAborted = errors.New("not deleted: atomic sweep aborted")
+	errArchiveExists = errors.New("archive ref already exists")
+)
+
 // ExecuteDeletions deletes the selected branches with safety checks.
-// - Never deletes the current branch
-// - Uses `git branch -d` by default; can use -D when ForceDelete is true
-// - Runs with bounded parallelism
-func ExecuteDeletions(ctx context.Context, r git.Runner, plan Plan, execOpts ExecuteOptions) (Result, error) {
+//   - Refuses to run while a git operation is in progress
+//   - Migrates to a renamed remote default branch first when detected
+//   - Switches to Plan.SwitchTo first when set, and back when the branch it
+//     switched off is not deleted, whether refused or stopped by an error
+//   - Never deletes the current branch
+//   - Deletes only branches merged into HEAD (or their live upstream), merged
+//     into the remote default branch, or with no unpushed commits, unless
+//     ForceDelete is true or the unpushed commits were pushed to RescueRemote
+//   - Deletes every approved branch in one `git update-ref --stdin` transaction
+//     that verifies each tip recorded in the plan; branches that moved since
+//     planning are left alone (or abort everything when Atomic is set)
+//   - Runs the read-only checks with bounded parallelism
+func ExecuteDeletions(ctx context.Context, r git.Runner, plan Plan, execOpts ExecuteOptions) (res Result, err error) {
 	if execOpts.MaxParallel <= 0 {
 		execOpts.MaxParallel = maxInt(2, runtime.NumCPU())
 	}
 
-	res := Result{Failed: make(map[string]error)}
+	res = Result{
+		Methods:   make(map[string]DeleteMethod),
+		Tips:      make(map[string]string),
+		Archived:  make(map[string]string),
+		Rescued:   make(map[string]string),
+		Repointed: make(map[string]string),
+		Failed:    make(map[string]error),
+	}
+	if execOpts.Now.IsZero() {
+		execOpts.Now = time.Now()
+	}
+	if plan.Operation.Name != "" {
+		return res, fmt.Errorf("%w: %s; finish or abort it before sweeping", ErrOperationInProgress, plan.Operation.Name)
+	}
+	if rename := plan.DefaultRename; rename != nil {
+		if err := applyDefaultRename(ctx, r, *rename); err != nil {
+			return res, fmt.Errorf("migrating to %s/%s: %w", rename.Remote, rename.To, err)
+		}
+		res.DefaultRenamed = rename
+		if rename.Branch != "" && plan.CurrentBranch == rename.Branch {
+			plan.CurrentBranch = rename.To
+		}
+	}
 	if len(plan.Candidates) == 0 {
 		return res, nil
 	}
+	if plan.SwitchTo != "" {
+		var ffErr error
+		if ffErr, err = switchToDefault(ctx, r, plan); err != nil {
+			return res, fmt.Errorf("switching to %s: %w", plan.SwitchTo, err)
+		}
+		res.SwitchedTo, res.FastForwardErr = plan.SwitchTo, ffErr
+		prev := plan.CurrentBranch
+		plan.CurrentBranch = plan.SwitchTo
+		// Every return from here on, including failed bundle, rescue or
+		// journal writes, goes back to prev unless it was deleted.
+		defer func() {
+			if backErr := switchBackUnlessDeleted(ctx, r, prev, &res); backErr != nil {
+				err = errors.Join(err, backErr)
+			}
+		}()
+	}
+
+	defaultRef := ""
+	if !execOpts.ForceDelete {
+		// Best effort: without a remote HEAD only the other proofs apply.
+		if short, err := git.RemoteDefaultRef(ctx, r, plan.Remote); err == nil && short != "" {
+			defaultRef = "refs/remotes/" + short
+		}
+	}
 
+	approved := make([]git.Branch, 0, len(plan.Candidates))
+	rescue := make(map[string]bool)
 	sem := make(chan struct{}, execOpts.MaxParallel)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
 	for _, b := range plan.Candidates {
-		branchName := b.Name
-		if branchName == plan.CurrentBranch {
-			mu.Lock()
-			res.Failed[branchName] = errors.New("refusing to delete current branch")
-			mu.Unlock()
+		if b.Ref == "" {
+			b.Ref = "refs/heads/" + b.Name
+		}
+		if b.Name == plan.CurrentBranch {
+			res.Failed[b.Name] = errors.New("refusing to delete current branch")
+			continue
+		}
+		if b.Tip == "" {
+			res.Failed[b.Name] = errUnknownTip
 			continue
 		}
 
@@ -55,28 +172,205 @@ func ExecuteDeletions(ctx context.Context, r git.Runner, plan Plan, execOpts Exe
 			defer wg.Done()
 			defer func() { <-sem }()
 
-			var err error
-			if execOpts.ForceDelete {
-				_, err = r.Run(ctx, "branch", "-D", branchName)
-			} else {
-				err = git.DeleteLocalBranch(ctx, r, branchName)
+			method, err := evaluatePolicy(ctx, r, b, defaultRef, execOpts.ForceDelete)
+			needed := execOpts.RescueRemote != "" && b.IsGone && (method == MethodForced || errors.Is(err, errNeedsForce)) && needsRescue(ctx, r, b)
+			if needed {
+				method, err = MethodRescued, nil
 			}
+			mu.Lock()
+			defer mu.Unlock()
 			if err != nil {
-				mu.Lock()
-				res.Failed[branchName] = fmt.Errorf("delete failed: %w", err)
-				mu.Unlock()
+				res.Failed[b.Name] = fmt.Errorf("delete refused: %w", err)
 				return
 			}
-			mu.Lock()
-			res.Deleted = append(res.Deleted, branchName)
-			mu.Unlock()
+			res.Methods[b.Name] = method
+			rescue[b.Name] = needed
+			approved = append(approved, b)
 		}()
 	}
-
 	wg.Wait()
+	sort.Slice(approved, func(i, j int) bool { return approved[i].Name < approved[j].Name })
+
+	moveTo, approved, err := archiveTargets(ctx, r, approved, execOpts, &res)
+	if err != nil {
+		return res, err
+	}
+
+	if execOpts.Atomic && len(res.Failed) > 0 {
+		abort(&res, approved, errAtomicAborted)
+		return res, nil
+	}
+
+	var toRescue []git.Branch
+	for _, b := range approved {
+		if rescue[b.Name] {
+			toRescue = append(toRescue, b)
+		}
+	}
+	if len(toRescue) > 0 {
+		if err := rescueBranches(ctx, r, execOpts.RescueRemote, toRescue, &res); err != nil {
+			return res, fmt.Errorf("rescue push: %w", err)
+		}
+		approved = withoutFailed(approved, res)
+		if execOpts.Atomic && len(res.Failed) > 0 {
+			abort(&res, approved, errAtomicAborted)
+			return res, nil
+		}
+	}
+
+	if execOpts.BundlePath != "" {
+		bundled, err := writeBundle(ctx, r, execOpts.BundlePath, approved)
+		if err != nil {
+			return res, fmt.Errorf("writing bundle: %w", err)
+		}
+		res.Bundled = bundled
+	}
+
+	if execOpts.Journal {
+		if err := writeJournal(ctx, r, approved, execOpts.CommandLine, execOpts.Now); err != nil {
+			return res, fmt.Errorf("writing sweep journal: %w", err)
+		}
+	}
+
+	deleted, err := deleteVerified(ctx, r, approved, moveTo, execOpts.Atomic, &res)
+	if err != nil {
+		abort(&res, deleted, fmt.Errorf("delete failed: %w", err))
+	} else {
+		for _, b := range approved {
+			if _, failed := res.Failed[b.Name]; !failed {
+				res.Deleted = append(res.Deleted, b.Name)
+				res.Tips[b.Name] = b.Tip
+				if ref := moveTo[b.Name]; ref != "" {
+					res.Archived[b.Name] = ref
+				}
+			}
+		}
+	}
+	if execOpts.Journal && len(approved) > 0 {
+		if err := finishJournal(ctx, r, execOpts.Now, res.Deleted); err != nil {
+			return res, fmt.Errorf("finishing sweep journal: %w", err)
+		}
+	}
+	if len(res.Deleted) == 0 {
+		return res, nil
+	}
+	// Archived branches keep their config so that `archive restore` brings
+	// back their upstream too.
+	var removed []string
+	for _, name := range res.Deleted {
+		if _, archived := res.Archived[name]; !archived {
+			removed = append(removed, name)
+		}
+	}
+	removeBranchConfigs(ctx, r, removed)
+	if execOpts.RepointDependents {
+		repointDependents(ctx, r, plan, &res)
+	}
 	return res, nil
 }
 
+// archiveTargets computes the archive ref of each approved branch when
+// archiving is requested, and fails the branches whose archive ref is taken.
+// It returns the targets keyed by branch name and the branches still approved.
+func archiveTargets(ctx context.Context, r git.Runner, approved []git.Branch, execOpts ExecuteOptions, res *Result) (map[string]string, []git.Branch, error) {
+	if execOpts.Archive == ArchiveNone {
+		return nil, approved, nil
+	}
+	existing := make(map[string]string)
+	for _, prefix := range []string{ArchiveRefPrefix, ArchiveTagPrefix} {
+		tips, err := git.RefTips(ctx, r, strings.TrimSuffix(prefix, "/"))
+		if err != nil {
+			return nil, nil, err
+		}
+		for ref, tip := range tips {
+			existing[ref] = tip
+		}
+	}
+	moveTo := make(map[string]string, len(approved))
+	kept := approved[:0]
+	for _, b := range approved {
+		ref := archiveRef(execOpts.Archive, b.Name, execOpts.Now)
+		if _, taken := existing[ref]; taken {
+			res.Failed[b.Name] = fmt.Errorf("%w: %s", errArchiveExists, ref)
+			delete(res.Methods, b.Name)
+			continue
+		}
+		moveTo[b.Name] = ref
+		kept = append(kept, b)
+	}
+	return moveTo, kept, nil
+}
+
+// deleteVerified deletes branches in one compare-and-swap transaction. When
+// the transaction fails outside atomic mode, it re-reads the branch tips,
+// records branches that moved as failed and retries once with the rest. On
+// error it returns the branches the failed transaction covered.
+func deleteVerified(ctx context.Context, r git.Runner, branches []git.Branch, moveTo map[string]string, atomic bool, res *Result) ([]git.Branch, error) {
+	err := git.DeleteRefs(ctx, r, refDeletions(branches, moveTo))
+	if err == nil || atomic {
+		return branches, err
+	}
+	tips, tipErr := git.RefTips(ctx, r, "refs/heads")
+	if tipErr != nil {
+		return branches, err
+	}
+	var unchanged []git.Branch
+	for _, b := range branches {
+		if tips[b.Ref] != b.Tip {
+			res.Failed[b.Name] = errMoved
+			delete(res.Methods, b.Name)
+			continue
+		}
+		unchanged = append(unchanged, b)
+	}
+	return unchanged, git.DeleteRefs(ctx, r, refDeletions(unchanged, moveTo))
+}
+
+func refDeletions(branches []git.Branch, moveTo map[string]string) []git.RefDeletion {
+	dels := make([]git.RefDeletion, 0, len(branches))
+	for _, b := range branches {
+		dels = append(dels, git.RefDeletion{Ref: b.Ref, OldTip: b.Tip, MoveTo: moveTo[b.Name]})
+	}
+	return dels
+}
+
+// withoutFailed returns the branches that have not failed so far.
+func withoutFailed(branches []git.Branch, res Result) []git.Branch {
+	kept := make([]git.Branch, 0, len(branches))
+	for _, b := range branches {
+		if _, failed := res.Failed[b.Name]; !failed {
+			kept = append(kept, b)
+		}
+	}
+	return kept
+}
+
+// abort marks every branch as failed with err.
+func abort(res *Result, branches []git.Branch, err error) {
+	for _, b := range branches {
+		res.Failed[b.Name] = err
+		delete(res.Methods, b.Name)
+	}
+}
+
+// removeBranchConfigs drops the `branch.<name>` config sections of deleted
+// branches, which update-ref (unlike `git branch -d`) leaves behind. It is best
+// effort: the branches are already gone and stale config is harmless.
+func removeBranchConfigs(ctx context.Context, r git.Runner, names []string) {
+	if len(names) == 0 {
+		return
+	}
+	present, err := git.BranchConfigNames(ctx, r)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		if present[name] {
+			_ = git.RemoveBranchConfig(ctx, r, name)
+		}
+	}
+}
+
 func maxInt(a, b int) int {
 	if a > b {
 		return a
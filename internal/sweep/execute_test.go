@@ -4,6 +4,7 @@
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/jmelosegui/git-sweep/internal/git"
@@ -11,9 +12,15 @@
 
 type fakeRunner struct {
 	calls  [][]string
+	inputs []string
 	failOn map[string]error
 }
 
+func (f *fakeRunner) RunInput(ctx context.Context, input string, args ...string) (git.Result, error) {
+	f.inputs = append(f.inputs, input)
+	return f.Run(ctx, args...)
+}
+
 func (f *fakeRunner) Run(_ context.Context, args ...string) (git.Result, error) {
 	f.calls = append(f.calls, append([]string{}, args...))
 	key := ""
@@ -41,32 +48,39 @@ func TestExecuteDeletions_SkipsCurrentBranch(t *testing.T) {
 	}
 }
 
-func TestExecuteDeletions_ForceUsesCapitalD(t *testing.T) {
+func TestExecuteDeletions_ForceDeletesInOneTransaction(t *testing.T) {
 	r := &fakeRunner{}
 	plan := Plan{
 		CurrentBranch: "main",
-		Candidates:    []git.Branch{{Name: "feature/x"}},
+		Candidates:    []git.Branch{{Name: "feature/x", Tip: "abc"}, {Name: "feature/y", Tip: "def"}},
 	}
-	_, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1, ForceDelete: true})
+	res, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1, ForceDelete: true})
 	if err != nil {
 		t.Fatalf("unexpected err: %v", err)
 	}
 	if len(r.calls) == 0 {
 		t.Fatalf("expected calls, got none")
 	}
-	want := []string{"branch", "-D", "feature/x"}
+	want := []string{"update-ref", "--stdin"}
 	if !reflect.DeepEqual(r.calls[0], want) {
 		t.Fatalf("unexpected args: got %v want %v", r.calls[0], want)
 	}
+	wantInput := "start\noption no-deref\ndelete refs/heads/feature/x abc\noption no-deref\ndelete refs/heads/feature/y def\nprepare\ncommit\n"
+	if len(r.inputs) != 1 || r.inputs[0] != wantInput {
+		t.Fatalf("unexpected transaction: %q", r.inputs)
+	}
+	if len(res.Deleted) != 2 || res.Methods["feature/x"] != MethodForced {
+		t.Fatalf("unexpected result: %+v", res)
+	}
 }
 
 func TestExecuteDeletions_ReportsDeleteFailure(t *testing.T) {
 	r := &fakeRunner{failOn: map[string]error{
-		"branch": errors.New("boom"),
+		"update-ref": errors.New("boom"),
 	}}
 	plan := Plan{
 		CurrentBranch: "main",
-		Candidates:    []git.Branch{{Name: "feature/y"}},
+		Candidates:    []git.Branch{{Name: "feature/y", Tip: "abc"}},
 	}
 	res, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1})
 	if err != nil {
@@ -76,3 +90,87 @@ func TestExecuteDeletions_ReportsDeleteFailure(t *testing.T) {
 		t.Fatalf("expected 1 failure, got %+v", res.Failed)
 	}
 }
+
+func TestExecuteDeletions_AtomicAbortsOnRefusal(t *testing.T) {
+	r := &fakeRunner{}
+	plan := Plan{
+		CurrentBranch: "main",
+		Candidates:    []git.Branch{{Name: "feature/x", Tip: "abc"}, {Name: "feature/unknown"}},
+	}
+	res, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1, ForceDelete: true, Atomic: true})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(res.Deleted) != 0 || len(res.Failed) != 2 {
+		t.Fatalf("expected nothing deleted, got %+v", res)
+	}
+	if len(r.inputs) != 0 {
+		t.Fatalf("expected no transaction, got %q", r.inputs)
+	}
+}
+
+func TestExecuteDeletions_RefusesDuringOperation(t *testing.T) {
+	r := &fakeRunner{}
+	plan := Plan{
+		CurrentBranch: "HEAD",
+		Operation:     git.Operation{Name: "rebase", Branch: "feature/x"},
+		Candidates:    []git.Branch{{Name: "feature/y"}},
+	}
+	_, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1})
+	if !errors.Is(err, ErrOperationInProgress) {
+		t.Fatalf("expected ErrOperationInProgress, got %v", err)
+	}
+	if len(r.calls) != 0 {
+		t.Fatalf("expected no git calls, got %v", r.calls)
+	}
+}
+
+func TestExecuteDeletions_RescuesOnlyGoneBranches(t *testing.T) {
+	r := &policyRunner{
+		out: map[string]string{"rev-list --count abc --not --remotes": "2\n"},
+		fail: map[string]bool{
+			"symbolic-ref refs/remotes/origin/HEAD":                  true,
+			"merge-base --is-ancestor abc refs/remotes/origin/stale": true,
+		},
+	}
+	plan := Plan{
+		Remote:        "origin",
+		CurrentBranch: "main",
+		Candidates:    []git.Branch{{Name: "stale", Ref: "refs/heads/stale", Tip: "abc", UpstreamRef: "refs/remotes/origin/stale"}},
+	}
+	res, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1, RescueRemote: "origin"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !errors.Is(res.Failed["stale"], errNeedsForce) || len(res.Rescued) != 0 {
+		t.Fatalf("expected the stale branch to be refused, not rescued: %+v", res)
+	}
+	for _, c := range r.calls {
+		if strings.HasPrefix(c, "push") {
+			t.Fatalf("unexpected push: %s", c)
+		}
+	}
+}
+
+func TestExecuteDeletions_ReportsFailedSwitchBack(t *testing.T) {
+	r := &policyRunner{
+		out: map[string]string{
+			"symbolic-ref refs/remotes/origin/HEAD":                       "refs/remotes/origin/main\n",
+			"for-each-ref --format=%(refname)%00%(objectname) refs/heads": "refs/heads/main\x00aaa\nrefs/heads/feature/x\x00\n",
+		},
+		fail: map[string]bool{"checkout feature/x --": true},
+	}
+	plan := Plan{
+		Remote:        "origin",
+		CurrentBranch: "feature/x",
+		SwitchTo:      "main",
+		Candidates:    []git.Branch{{Name: "feature/x", Ref: "refs/heads/feature/x"}},
+	}
+	res, err := ExecuteDeletions(context.Background(), r, plan, ExecuteOptions{MaxParallel: 1})
+	if err == nil || !strings.Contains(err.Error(), "switching back to feature/x") {
+		t.Fatalf("expected the failed switch back to be reported, got %v", err)
+	}
+	if res.SwitchedTo != "main" || !errors.Is(res.Failed["feature/x"], errUnknownTip) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+}
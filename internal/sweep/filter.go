@@ -3,6 +3,7 @@
 import (
 	"regexp"
 	"sort"
+	"time"
 
 	"github.com/jmelosegui/git-sweep/internal/git"
 )
@@ -10,29 +11,33 @@
 // FilterOptions controls how branches are selected for deletion.
 // Include/Exclude are optional regex patterns applied to branch names.
 // ProtectedNames are exact matches that must never be deleted.
+// ProtectRecent protects branches whose LastCheckout is within that window.
+// StaleAfter, when positive, also selects branches that are not gone but have
+// not been checked out for at least that long; branches with no known checkout
+// are never treated as stale. Now defaults to time.Now when zero.
+// ProtectStashed protects branches referenced by stash entries (see Branch.Stashes).
 type FilterOptions struct {
 	IncludePattern  string
 	ExcludePattern  string
 	ProtectedNames  []string
 	ProtectCurrent  bool
 	ProtectUpstream bool
+	ProtectRecent   time.Duration
+	StaleAfter      time.Duration
+	ProtectStashed  bool
+	Now             time.Time
 }
 
-// SelectBranchesToDelete returns branches that are marked gone and pass filters/protections.
-func SelectBranchesToDelete(branches []git.Branch, current string, currentUpstream string, opts FilterOptions) ([]git.Branch, error) {
-	var includeRe, excludeRe *regexp.Regexp
-	var err error
-	if opts.IncludePattern != "" {
-		includeRe, err = regexp.Compile(opts.IncludePattern)
-		if err != nil {
-			return nil, err
-		}
-	}
-	if opts.ExcludePattern != "" {
-		excludeRe, err = regexp.Compile(opts.ExcludePattern)
-		if err != nil {
-			return nil, err
-		}
+// SelectBranchesToDelete returns branches that are marked gone (or stale) and pass filters/protections.
+// Symbolic branches (see Branch.SymrefTarget) are never selected: they are
+// aliases whose fate follows the branch they point to.
+// current is the short name of the checked-out branch; currentUpstreamRef is the
+// full ref of its upstream, which only protects a local branch when it is one
+// (e.g., "refs/heads/base" for a branch stacked on a local base).
+func SelectBranchesToDelete(branches []git.Branch, current string, currentUpstreamRef string, opts FilterOptions) ([]git.Branch, error) {
+	includeRe, excludeRe, err := compilePatterns(opts.IncludePattern, opts.ExcludePattern)
+	if err != nil {
+		return nil, err
 	}
 
 	protected := make(map[string]struct{}, len(opts.ProtectedNames)+2)
@@ -42,22 +47,33 @@ func SelectBranchesToDelete(branches []git.Branch, current string, currentUpstre
 	if opts.ProtectCurrent && current != "" {
 		protected[current] = struct{}{}
 	}
-	if opts.ProtectUpstream && currentUpstream != "" {
-		protected[currentUpstream] = struct{}{}
+
+	now := opts.Now
+	if now.IsZero() {
+		now = time.Now()
 	}
 
 	var selected []git.Branch
 	for _, b := range branches {
-		if !b.IsGone {
+		if b.SymrefTarget != "" {
+			continue
+		}
+		if !b.IsGone && !isStale(b, opts.StaleAfter, now) {
 			continue
 		}
 		if _, isProt := protected[b.Name]; isProt {
 			continue
 		}
-		if includeRe != nil && !includeRe.MatchString(b.Name) {
+		if opts.ProtectUpstream && currentUpstreamRef != "" && b.Ref == currentUpstreamRef {
 			continue
 		}
-		if excludeRe != nil && excludeRe.MatchString(b.Name) {
+		if opts.ProtectRecent > 0 && !b.LastCheckout.IsZero() && now.Sub(b.LastCheckout) < opts.ProtectRecent {
+			continue
+		}
+		if opts.ProtectStashed && len(b.Stashes) > 0 {
+			continue
+		}
+		if !matchesPatterns(b.Name, includeRe, excludeRe) {
 			continue
 		}
 		selected = append(selected, b)
@@ -66,3 +82,35 @@ func SelectBranchesToDelete(branches []git.Branch, current string, currentUpstre
 	sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
 	return selected, nil
 }
+
+// compilePatterns compiles the optional include and exclude regexes; an empty
+// pattern yields a nil regexp.
+func compilePatterns(include, exclude string) (includeRe, excludeRe *regexp.Regexp, err error) {
+	if include != "" {
+		if includeRe, err = regexp.Compile(include); err != nil {
+			return nil, nil, err
+		}
+	}
+	if exclude != "" {
+		if excludeRe, err = regexp.Compile(exclude); err != nil {
+			return nil, nil, err
+		}
+	}
+	return includeRe, excludeRe, nil
+}
+
+// matchesPatterns reports whether name passes the include and exclude regexes.
+func matchesPatterns(name string, includeRe, excludeRe *regexp.Regexp) bool {
+	if includeRe != nil && !includeRe.MatchString(name) {
+		return false
+	}
+	return excludeRe == nil || !excludeRe.MatchString(name)
+}
+
+// isStale reports whether b has a known last checkout older than staleAfter.
+func isStale(b git.Branch, staleAfter time.Duration, now time.Time) bool {
+	if staleAfter <= 0 || b.LastCheckout.IsZero() {
+		return false
+	}
+	return now.Sub(b.LastCheckout) >= staleAfter
+}
@@ -1,7 +1,9 @@
 package sweep
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/jmelosegui/git-sweep/internal/git"
 )
@@ -23,7 +25,7 @@ func TestSelectBranchesToDelete(t *testing.T) {
 		ProtectUpstream: true,
 	}
 
-	selected, err := SelectBranchesToDelete(branches, "hotfix/x", "origin/main", opts)
+	selected, err := SelectBranchesToDelete(branches, "hotfix/x", "refs/remotes/origin/main", opts)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -31,3 +33,74 @@ func TestSelectBranchesToDelete(t *testing.T) {
 		t.Fatalf("unexpected selection: %#v", selected)
 	}
 }
+
+func TestSelectBranchesToDelete_SkipsSymbolicBranches(t *testing.T) {
+	branches := []git.Branch{
+		{Ref: "refs/heads/alias", Name: "alias", IsGone: true, SymrefTarget: "refs/heads/feature/a"},
+		{Ref: "refs/heads/feature/b", Name: "feature/b", IsGone: true},
+	}
+
+	selected, err := SelectBranchesToDelete(branches, "main", "", FilterOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 1 || selected[0].Name != "feature/b" {
+		t.Fatalf("unexpected selection: %#v", selected)
+	}
+}
+
+func TestSelectBranchesToDelete_UpstreamComparedByRef(t *testing.T) {
+	branches := []git.Branch{
+		{Ref: "refs/heads/origin/main", Name: "origin/main", IsGone: true},
+		{Ref: "refs/heads/base", Name: "base", IsGone: true},
+	}
+
+	// The current branch tracks refs/remotes/origin/main: the local branch
+	// named origin/main is unrelated and must not be protected.
+	selected, err := SelectBranchesToDelete(branches, "topic", "refs/remotes/origin/main", FilterOptions{ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 2 {
+		t.Fatalf("expected both branches selected, got %+v", selected)
+	}
+
+	// A local upstream is protected.
+	selected, err = SelectBranchesToDelete(branches, "topic", "refs/heads/base", FilterOptions{ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 1 || selected[0].Name != "origin/main" {
+		t.Fatalf("unexpected selection: %+v", selected)
+	}
+}
+
+func TestSelectBranchesToDelete_CheckoutHistory(t *testing.T) {
+	now := time.Date(2026, 1, 31, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	branches := []git.Branch{
+		{Name: "gone/yesterday", IsGone: true, LastCheckout: now.Add(-1 * day)},
+		{Name: "gone/old", IsGone: true, LastCheckout: now.Add(-20 * day)},
+		{Name: "gone/unknown", IsGone: true},
+		{Name: "live/old", LastCheckout: now.Add(-40 * day)},
+		{Name: "live/recent", LastCheckout: now.Add(-10 * day)},
+		{Name: "live/unknown"},
+	}
+
+	selected, err := SelectBranchesToDelete(branches, "main", "", FilterOptions{
+		ProtectRecent: 7 * day,
+		StaleAfter:    30 * day,
+		Now:           now,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, b := range selected {
+		names = append(names, b.Name)
+	}
+	want := []string{"gone/old", "gone/unknown", "live/old"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("unexpected selection: got %v want %v", names, want)
+	}
+}
@@ -0,0 +1,271 @@
+package sweep
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// journalFile is the deletion journal, relative to the common git directory.
+// It holds one JSON object per sweep run, oldest first.
+const journalFile = "sweep/journal.jsonl"
+
+// ErrNothingToUndo is returned by Undo when every journaled run is undone.
+var ErrNothingToUndo = errors.New("no sweep run to undo")
+
+// ErrNotJournaled is returned by RestoreFromJournal when the branch is not in the journal.
+var ErrNotJournaled = errors.New("branch not found in the sweep journal")
+
+// JournalRun records one sweep run. It is written as Pending before any branch
+// is deleted and then narrowed to the branches actually deleted; a run left
+// Pending by a crash is not undone by Undo but can still be restored by name.
+type JournalRun struct {
+	Time     time.Time       `json:"time"`
+	Command  []string        `json:"command"`
+	Pending  bool            `json:"pending,omitempty"`
+	Undone   bool            `json:"undone,omitempty"`
+	Branches []JournalBranch `json:"branches"`
+}
+
+// JournalBranch is everything needed to recreate a deleted branch.
+type JournalBranch struct {
+	Name     string            `json:"name"`
+	Ref      string            `json:"ref"`
+	Tip      string            `json:"tip"`
+	Upstream string            `json:"upstream,omitempty"`
+	Config   []git.ConfigEntry `json:"config,omitempty"`
+}
+
+// RestoreResult reports what a restore did for each branch.
+// Skipped holds branches that already exist again and were left untouched.
+type RestoreResult struct {
+	Restored []JournalBranch
+	Skipped  []JournalBranch
+}
+
+// writeJournal appends a pending run covering branches, with their upstream
+// config, to the journal. It runs before deletion so a failure here stops the
+// sweep; finishJournal settles the run afterwards.
+func writeJournal(ctx context.Context, r git.Runner, branches []git.Branch, command []string, now time.Time) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	cfg, err := git.BranchConfig(ctx, r)
+	if err != nil {
+		return err
+	}
+	run := JournalRun{Time: now, Command: command, Pending: true}
+	for _, b := range branches {
+		run.Branches = append(run.Branches, JournalBranch{
+			Name:     b.Name,
+			Ref:      b.Ref,
+			Tip:      b.Tip,
+			Upstream: b.Upstream,
+			Config:   cfg[b.Name],
+		})
+	}
+	path, err := journalPath(ctx, r)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	line, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// finishJournal settles the pending run written at now: it keeps only the
+// branches in deleted, or drops the run when nothing was deleted, so that Undo
+// never restores a run that did not happen.
+func finishJournal(ctx context.Context, r git.Runner, now time.Time, deleted []string) error {
+	path, err := journalPath(ctx, r)
+	if err != nil {
+		return err
+	}
+	runs, err := readJournalFile(path)
+	if err != nil {
+		return err
+	}
+	gone := make(map[string]bool, len(deleted))
+	for _, name := range deleted {
+		gone[name] = true
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if !runs[i].Pending || !runs[i].Time.Equal(now) {
+			continue
+		}
+		var kept []JournalBranch
+		for _, b := range runs[i].Branches {
+			if gone[b.Name] {
+				kept = append(kept, b)
+			}
+		}
+		if len(kept) == 0 {
+			runs = append(runs[:i], runs[i+1:]...)
+		} else {
+			runs[i].Branches, runs[i].Pending = kept, false
+		}
+		return writeJournalFile(path, runs)
+	}
+	return nil
+}
+
+// ReadJournal returns all journaled runs, oldest first. A missing journal is empty.
+func ReadJournal(ctx context.Context, r git.Runner) ([]JournalRun, error) {
+	path, err := journalPath(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	return readJournalFile(path)
+}
+
+// Undo recreates the branches of the most recent completed run that was not
+// undone yet, with their config, and marks that run as undone.
+func Undo(ctx context.Context, r git.Runner) (RestoreResult, error) {
+	path, err := journalPath(ctx, r)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	runs, err := readJournalFile(path)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].Undone || runs[i].Pending {
+			continue
+		}
+		res, err := restoreBranches(ctx, r, runs[i].Branches)
+		if err != nil {
+			return res, err
+		}
+		runs[i].Undone = true
+		return res, writeJournalFile(path, runs)
+	}
+	return RestoreResult{}, ErrNothingToUndo
+}
+
+// RestoreFromJournal recreates branch name, with its config, from the most
+// recent journal entry that mentions it.
+func RestoreFromJournal(ctx context.Context, r git.Runner, name string) (RestoreResult, error) {
+	runs, err := ReadJournal(ctx, r)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	for i := len(runs) - 1; i >= 0; i-- {
+		for _, b := range runs[i].Branches {
+			if b.Name == name {
+				return restoreBranches(ctx, r, []JournalBranch{b})
+			}
+		}
+	}
+	return RestoreResult{}, fmt.Errorf("%w: %s", ErrNotJournaled, name)
+}
+
+// restoreBranches creates the missing branches in one transaction and then
+// re-adds their config sections. Branches that exist again are skipped.
+func restoreBranches(ctx context.Context, r git.Runner, branches []JournalBranch) (RestoreResult, error) {
+	var res RestoreResult
+	existing, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return res, err
+	}
+	var creates []git.RefCreation
+	for _, b := range branches {
+		if _, ok := existing[b.Ref]; ok {
+			res.Skipped = append(res.Skipped, b)
+			continue
+		}
+		creates = append(creates, git.RefCreation{Ref: b.Ref, Tip: b.Tip})
+		res.Restored = append(res.Restored, b)
+	}
+	if err := git.CreateRefs(ctx, r, creates); err != nil {
+		return RestoreResult{}, err
+	}
+	present, err := git.BranchConfigNames(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	for _, b := range res.Restored {
+		if present[b.Name] {
+			continue
+		}
+		if err := git.AddBranchConfig(ctx, r, b.Name, b.Config); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+func journalPath(ctx context.Context, r git.Runner) (string, error) {
+	dir, err := git.CommonDir(ctx, r)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, filepath.FromSlash(journalFile)), nil
+}
+
+func readJournalFile(path string) ([]JournalRun, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var runs []JournalRun
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+		var run JournalRun
+		if err := json.Unmarshal([]byte(line), &run); err != nil {
+			return nil, fmt.Errorf("corrupt sweep journal %s: %w", path, err)
+		}
+		runs = append(runs, run)
+	}
+	return runs, sc.Err()
+}
+
+// writeJournalFile rewrites the journal through a temporary file so a crash
+// never leaves it half written.
+func writeJournalFile(path string, runs []JournalRun) error {
+	var b strings.Builder
+	for _, run := range runs {
+		line, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		b.Write(line)
+		b.WriteByte('\n')
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
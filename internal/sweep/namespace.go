@@ -0,0 +1,171 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// NamespaceSection is the config section holding per-namespace rules, e.g.
+//
+//	[sweepref "refs/pull/"]
+//		olderThan = 30d
+//		remote = origin
+const NamespaceSection = "sweepref"
+
+// reservedNamespaces are handled by the branch and tag sweeps, fetch --prune
+// and the archive commands, and cannot be swept as arbitrary namespaces.
+var reservedNamespaces = []string{"refs/heads/", "refs/tags/", "refs/remotes/", ArchiveRefPrefix}
+
+// NamespaceRule says when refs under Prefix (e.g., "refs/pull/") are swept:
+// when the commit they point to is older than OlderThan, or, with Remote set,
+// when the remote has no ref of the same name. RemotePrefix is where the remote
+// keeps those refs; it defaults to Prefix, and differs for namespaces such as
+// refs/prefetch/remotes/origin/, which mirrors the remote's refs/heads/.
+type NamespaceRule struct {
+	Prefix       string
+	OlderThan    time.Duration
+	Remote       string
+	RemotePrefix string
+}
+
+// StaleRef is a ref selected by a NamespaceRule. Object is what it pointed to
+// when planning and Reason says which criterion matched.
+type StaleRef struct {
+	Ref    string
+	Object string
+	Date   time.Time
+	Reason string
+}
+
+// RefPlan lists the refs to sweep from arbitrary namespaces.
+type RefPlan struct {
+	Rules      []NamespaceRule
+	Candidates []StaleRef
+}
+
+// NormalizeNamespace validates a namespace and returns it with a trailing
+// slash. It must lie under refs/ and must not overlap the namespaces git-sweep
+// manages elsewhere.
+func NormalizeNamespace(prefix string) (string, error) {
+	prefix = strings.TrimSuffix(strings.TrimSpace(prefix), "*")
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if !strings.HasPrefix(prefix, "refs/") || prefix == "refs/" {
+		return "", fmt.Errorf("invalid namespace %q: use a prefix under refs/, e.g. refs/pull/", prefix)
+	}
+	for _, reserved := range reservedNamespaces {
+		if strings.HasPrefix(prefix, reserved) || strings.HasPrefix(reserved, prefix) {
+			return "", fmt.Errorf("namespace %q overlaps %s, which git sweep manages separately", prefix, reserved)
+		}
+	}
+	return prefix, nil
+}
+
+// NamespaceRules reads the rules configured in [sweepref "<namespace>"]
+// sections, sorted by namespace. Keys are olderThan (an age such as 30d),
+// remote and remotePrefix.
+func NamespaceRules(ctx context.Context, r git.Runner) ([]NamespaceRule, error) {
+	cfg, err := git.SubsectionConfig(ctx, r, NamespaceSection)
+	if err != nil {
+		return nil, err
+	}
+	var rules []NamespaceRule
+	for name, entries := range cfg {
+		rule := NamespaceRule{Prefix: name}
+		for _, e := range entries {
+			switch e.Key {
+			case "olderthan":
+				if rule.OlderThan, err = ParseAge(e.Value); err != nil {
+					return nil, fmt.Errorf("%s.%s.olderThan: %w", NamespaceSection, name, err)
+				}
+			case "remote":
+				rule.Remote = e.Value
+			case "remoteprefix":
+				rule.RemotePrefix = e.Value
+			}
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].Prefix < rules[j].Prefix })
+	return rules, nil
+}
+
+// BuildRefPlan applies each rule to the refs under its namespace. A rule
+// without OlderThan or Remote is an error, as is failing to reach a remote:
+// an unreachable remote must not look like one without refs.
+func BuildRefPlan(ctx context.Context, r git.Runner, rules []NamespaceRule, now time.Time) (RefPlan, error) {
+	plan := RefPlan{}
+	if now.IsZero() {
+		now = time.Now()
+	}
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		prefix, err := NormalizeNamespace(rule.Prefix)
+		if err != nil {
+			return plan, err
+		}
+		rule.Prefix = prefix
+		if rule.OlderThan <= 0 && rule.Remote == "" {
+			return plan, fmt.Errorf("namespace %s: set an age (olderThan) or a remote to compare against", prefix)
+		}
+		if rule.RemotePrefix == "" {
+			rule.RemotePrefix = prefix
+		}
+		plan.Rules = append(plan.Rules, rule)
+
+		refs, err := git.ListRefs(ctx, r, prefix)
+		if err != nil {
+			return plan, err
+		}
+		if len(refs) == 0 {
+			continue
+		}
+		var onRemote map[string]bool
+		if rule.Remote != "" {
+			if onRemote, err = git.RemoteRefs(ctx, r, rule.Remote, rule.RemotePrefix); err != nil {
+				return plan, err
+			}
+		}
+		for _, ref := range refs {
+			if seen[ref.Name] {
+				// Nested namespaces: list each ref once.
+				continue
+			}
+			if reason := staleReason(ref, rule, onRemote, now); reason != "" {
+				seen[ref.Name] = true
+				plan.Candidates = append(plan.Candidates, StaleRef{Ref: ref.Name, Object: ref.Object, Date: ref.Date, Reason: reason})
+			}
+		}
+	}
+	sort.Slice(plan.Candidates, func(i, j int) bool { return plan.Candidates[i].Ref < plan.Candidates[j].Ref })
+	return plan, nil
+}
+
+// staleReason returns why ref matches rule, or "" when it is kept. Refs that
+// do not point at a commit have no date and are never swept by age.
+func staleReason(ref git.Ref, rule NamespaceRule, onRemote map[string]bool, now time.Time) string {
+	if onRemote != nil && !onRemote[rule.RemotePrefix+strings.TrimPrefix(ref.Name, rule.Prefix)] {
+		return "not on " + rule.Remote
+	}
+	if rule.OlderThan > 0 && !ref.Date.IsZero() && now.Sub(ref.Date) >= rule.OlderThan {
+		return "last commit " + ref.Date.Format("2006-01-02")
+	}
+	return ""
+}
+
+// DeleteStaleRefs deletes the planned refs in one `update-ref` transaction
+// that verifies each still points at its planned object; if any moved,
+// nothing is deleted.
+func DeleteStaleRefs(ctx context.Context, r git.Runner, plan RefPlan) error {
+	dels := make([]git.RefDeletion, 0, len(plan.Candidates))
+	for _, c := range plan.Candidates {
+		dels = append(dels, git.RefDeletion{Ref: c.Ref, OldTip: c.Object})
+	}
+	return git.DeleteRefs(ctx, r, dels)
+}
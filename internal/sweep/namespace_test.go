@@ -0,0 +1,61 @@
+package sweep
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestNormalizeNamespace(t *testing.T) {
+	cases := map[string]string{
+		"refs/pull":    "refs/pull/",
+		"refs/pull/*":  "refs/pull/",
+		"refs/review/": "refs/review/",
+		"refs/":        "",
+		"pull/":        "",
+		"refs/heads/x": "",
+		"refs/tags":    "",
+	}
+	for in, want := range cases {
+		got, err := NormalizeNamespace(in)
+		if (err != nil) != (want == "") || got != want {
+			t.Errorf("NormalizeNamespace(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+}
+
+func TestBuildRefPlan(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	old := strconv.FormatInt(now.Add(-60*24*time.Hour).Unix(), 10)
+	recent := strconv.FormatInt(now.Add(-time.Hour).Unix(), 10)
+	r := &policyRunner{out: map[string]string{
+		"for-each-ref --format=%(refname)%00%(objectname)%00%(committerdate:unix) refs/pull/": "" +
+			"refs/pull/1/head\x00aaa\x00" + old + "\n" +
+			"refs/pull/2/head\x00bbb\x00" + recent + "\n",
+		"for-each-ref --format=%(refname)%00%(objectname)%00%(committerdate:unix) refs/prefetch/remotes/origin/": "" +
+			"refs/prefetch/remotes/origin/main\x00ccc\x00" + recent + "\n" +
+			"refs/prefetch/remotes/origin/gone\x00ddd\x00" + recent + "\n",
+		"ls-remote --refs origin refs/heads/*": "ccc\trefs/heads/main\n",
+	}}
+	rules := []NamespaceRule{
+		{Prefix: "refs/pull", OlderThan: 30 * 24 * time.Hour},
+		{Prefix: "refs/prefetch/remotes/origin/", Remote: "origin", RemotePrefix: "refs/heads/"},
+	}
+
+	plan, err := BuildRefPlan(context.Background(), r, rules, now)
+	if err != nil {
+		t.Fatalf("BuildRefPlan: %v", err)
+	}
+	if len(plan.Candidates) != 2 ||
+		plan.Candidates[0].Ref != "refs/prefetch/remotes/origin/gone" || plan.Candidates[0].Reason != "not on origin" ||
+		plan.Candidates[1].Ref != "refs/pull/1/head" {
+		t.Fatalf("unexpected candidates: %+v", plan.Candidates)
+	}
+}
+
+func TestBuildRefPlan_RuleWithoutCriterion(t *testing.T) {
+	if _, err := BuildRefPlan(context.Background(), &policyRunner{}, []NamespaceRule{{Prefix: "refs/pull/"}}, time.Time{}); err == nil {
+		t.Fatal("expected an error for a rule without age or remote")
+	}
+}
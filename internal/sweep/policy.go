@@ -0,0 +1,58 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// DeleteMethod records why a branch was allowed to be deleted.
+type DeleteMethod string
+
+const (
+	// MethodSafe means the tip is merged into HEAD, or into the branch's
+	// upstream when that still exists; the check `git branch -d` performs.
+	MethodSafe DeleteMethod = "safe"
+	// MethodMerged means the tip is merged into the remote default branch.
+	MethodMerged DeleteMethod = "merged"
+	// MethodPushed means every commit is reachable from a remote-tracking ref.
+	MethodPushed DeleteMethod = "pushed"
+	// MethodRescued means the unpushed commits were pushed to the rescue remote.
+	MethodRescued DeleteMethod = "rescued"
+	// MethodForced means the caller asked for --force.
+	MethodForced DeleteMethod = "forced"
+)
+
+// errNeedsForce is returned when a branch is not proven safe to delete.
+var errNeedsForce = errors.New("not fully merged and has unpushed commits; use --force to delete")
+
+// evaluatePolicy decides, without changing anything, whether b may be deleted.
+// A branch passes when it is merged where `git branch -d` would look, when it
+// is merged into defaultRef, or when it has no unpushed commits; with force
+// every branch passes.
+func evaluatePolicy(ctx context.Context, r git.Runner, b git.Branch, defaultRef string, force bool) (DeleteMethod, error) {
+	if force {
+		return MethodForced, nil
+	}
+	commit := b.Tip
+	if commit == "" {
+		commit = b.Ref
+	}
+	target := "HEAD"
+	if !b.IsGone && b.UpstreamRef != "" {
+		target = b.UpstreamRef
+	}
+	if merged, _ := git.IsAncestor(ctx, r, commit, target); merged {
+		return MethodSafe, nil
+	}
+	if defaultRef != "" {
+		if merged, _ := git.IsAncestor(ctx, r, commit, defaultRef); merged {
+			return MethodMerged, nil
+		}
+	}
+	if n, err := git.UnpushedCount(ctx, r, commit); err == nil && n == 0 {
+		return MethodPushed, nil
+	}
+	return "", errNeedsForce
+}
@@ -0,0 +1,64 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// policyRunner fails commands whose joined args are in fail and returns
+// canned stdout from out; every call is recorded.
+type policyRunner struct {
+	out   map[string]string
+	fail  map[string]bool
+	calls []string
+}
+
+func (p *policyRunner) Run(_ context.Context, args ...string) (git.Result, error) {
+	key := strings.Join(args, " ")
+	p.calls = append(p.calls, key)
+	if p.fail[key] {
+		return git.Result{ExitCode: 1}, errors.New("exit status 1")
+	}
+	return git.Result{Stdout: p.out[key]}, nil
+}
+
+func TestEvaluatePolicy(t *testing.T) {
+	const def = "refs/remotes/origin/main"
+	gone := git.Branch{Ref: "refs/heads/topic", Name: "topic", Tip: "abc", IsGone: true}
+	live := git.Branch{Ref: "refs/heads/topic", Name: "topic", Tip: "abc", UpstreamRef: "refs/remotes/origin/topic"}
+	inHead := "merge-base --is-ancestor abc HEAD"
+	inUpstream := "merge-base --is-ancestor abc refs/remotes/origin/topic"
+	inDefault := "merge-base --is-ancestor abc " + def
+	unpushed := "rev-list --count abc --not --remotes"
+
+	cases := []struct {
+		name    string
+		b       git.Branch
+		r       *policyRunner
+		force   bool
+		want    DeleteMethod
+		wantErr error
+	}{
+		{"forced", gone, &policyRunner{}, true, MethodForced, nil},
+		{"merged into HEAD", gone, &policyRunner{}, false, MethodSafe, nil},
+		{"merged into live upstream", live, &policyRunner{fail: map[string]bool{inHead: true}}, false, MethodSafe, nil},
+		{"merged into default", gone, &policyRunner{fail: map[string]bool{inHead: true}}, false, MethodMerged, nil},
+		{"pushed", gone, &policyRunner{fail: map[string]bool{inHead: true, inDefault: true}, out: map[string]string{unpushed: "0"}}, false, MethodPushed, nil},
+		{"unproven", live, &policyRunner{fail: map[string]bool{inUpstream: true, inDefault: true}, out: map[string]string{unpushed: "2"}}, false, "", errNeedsForce},
+	}
+	for _, c := range cases {
+		got, err := evaluatePolicy(context.Background(), c.r, c.b, def, c.force)
+		if got != c.want || !errors.Is(err, c.wantErr) {
+			t.Errorf("%s: got (%q, %v) want (%q, %v)", c.name, got, err, c.want, c.wantErr)
+		}
+		for _, call := range c.r.calls {
+			if strings.HasPrefix(call, "branch") || strings.HasPrefix(call, "update-ref") {
+				t.Errorf("%s: policy must be read-only, ran %q", c.name, call)
+			}
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package sweep
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// Sources a Recoverable can come from, most reliable first.
+const (
+	SourceBranchReflog = "branch reflog"
+	SourceHeadReflog   = "HEAD reflog"
+	SourceRemoteReflog = "remote-tracking reflog"
+	SourceDangling     = "dangling commit"
+)
+
+// danglingPrefix names branches recovered from dangling commits, which carry
+// no name of their own.
+const danglingPrefix = "recovered/"
+
+// Recoverable is the likely tip of a branch that no longer exists.
+// Seen is when the branch was last observed at Tip (the commit date for
+// dangling commits); Date and Subject describe the commit itself.
+type Recoverable struct {
+	Name    string
+	Tip     string
+	Source  string
+	Seen    time.Time
+	Date    time.Time
+	Subject string
+}
+
+// FindRecoverable looks for branches deleted without a journal entry (by hand
+// or by older versions) that were last seen at or after since. It combines
+// leftover branch reflogs, "checkout: moving from X to Y" entries in the HEAD
+// reflog, leftover remote-tracking reflogs and dangling commits from git fsck.
+// Names that exist as branches, remote-tracking branches or tags are skipped,
+// as are tips whose objects have been pruned. Results are newest first.
+func FindRecoverable(ctx context.Context, r git.Runner, since time.Time) ([]Recoverable, error) {
+	heads, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	remotes, err := git.RefTips(ctx, r, "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	tags, err := git.RefTips(ctx, r, "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	remoteNames, err := git.Remotes(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	tracked := remoteBranchNames(remotes, remoteNames)
+	taken := func(name string) bool {
+		if _, ok := heads["refs/heads/"+name]; ok {
+			return true
+		}
+		if _, ok := tags["refs/tags/"+name]; ok {
+			return true
+		}
+		return tracked[name]
+	}
+
+	var found []Recoverable
+	branchLogs, err := git.OrphanedReflogs(ctx, r, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range branchLogs {
+		found = append(found, Recoverable{Name: strings.TrimPrefix(l.Ref, "refs/heads/"), Tip: l.Tip, Source: SourceBranchReflog, Seen: l.Time})
+	}
+	headLog, err := git.HeadReflog(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, headReflogTips(headLog)...)
+	remoteLogs, err := git.OrphanedReflogs(ctx, r, "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	for _, l := range remoteLogs {
+		// refs/remotes/<remote>/<branch>: the local branch was usually <branch>.
+		parts := strings.SplitN(strings.TrimPrefix(l.Ref, "refs/remotes/"), "/", 2)
+		if len(parts) != 2 || parts[1] == "HEAD" {
+			continue
+		}
+		found = append(found, Recoverable{Name: parts[1], Tip: l.Tip, Source: SourceRemoteReflog, Seen: l.Time})
+	}
+
+	// Keep the first (most reliable) observation of each name.
+	seenNames := make(map[string]bool)
+	seenTips := make(map[string]bool)
+	var named []Recoverable
+	for _, c := range found {
+		if c.Name == "" || seenNames[c.Name] || taken(c.Name) || c.Seen.Before(since) {
+			continue
+		}
+		seenNames[c.Name] = true
+		seenTips[c.Tip] = true
+		named = append(named, c)
+	}
+
+	dangling, err := git.DanglingCommits(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	for _, sha := range dangling {
+		if seenTips[sha] {
+			continue
+		}
+		named = append(named, Recoverable{Name: danglingPrefix + git.ShortSHA(sha), Tip: sha, Source: SourceDangling})
+	}
+
+	tips := make([]string, 0, len(named))
+	for _, c := range named {
+		tips = append(tips, c.Tip)
+	}
+	info, err := git.CommitInfo(ctx, r, tips)
+	if err != nil {
+		return nil, err
+	}
+	var out []Recoverable
+	for _, c := range named {
+		commit, ok := info[c.Tip]
+		if !ok {
+			continue
+		}
+		c.Date, c.Subject = commit.Time, commit.Subject
+		if c.Source == SourceDangling {
+			if c.Date.Before(since) {
+				continue
+			}
+			c.Seen = c.Date
+		}
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Seen.After(out[j].Seen) })
+	return out, nil
+}
+
+// headReflogTips derives branch tips from checkout entries of a newest-first
+// HEAD reflog. "moving from X to Y" means X was at the commit HEAD held before
+// the entry (the next older one) and Y at the commit of the entry itself. Only
+// the newest observation of each name is kept.
+func headReflogTips(entries []git.ReflogEntry) []Recoverable {
+	var out []Recoverable
+	seen := make(map[string]bool)
+	add := func(name, tip string, when time.Time) {
+		if name == "" || name == "HEAD" || tip == "" || seen[name] || detachedName(name, tip) {
+			return
+		}
+		seen[name] = true
+		out = append(out, Recoverable{Name: name, Tip: tip, Source: SourceHeadReflog, Seen: when})
+	}
+	for i, e := range entries {
+		from, to, ok := git.CheckoutSubject(e.Subject)
+		if !ok {
+			continue
+		}
+		if i+1 < len(entries) {
+			add(from, entries[i+1].Commit, e.Time)
+		}
+		add(to, e.Commit, e.Time)
+	}
+	return out
+}
+
+// detachedName reports whether a checkout entry names a commit rather than a
+// branch: leaving a detached HEAD records the full object name, and detaching
+// records the abbreviation that was typed. Short hex-like branch names such as
+// "cafe" are not mistaken for abbreviations.
+func detachedName(name, tip string) bool {
+	if git.IsFullSHA(name) {
+		return true
+	}
+	return len(name) >= 7 && strings.HasPrefix(tip, name) && strings.Trim(name, "0123456789abcdef") == ""
+}
+
+// remoteBranchNames returns the branch names of remote-tracking refs, i.e.
+// <branch> for each refs/remotes/<remote>/<branch> of a configured remote.
+func remoteBranchNames(tips map[string]string, remotes []string) map[string]bool {
+	names := make(map[string]bool)
+	for ref := range tips {
+		for _, remote := range remotes {
+			if name, ok := strings.CutPrefix(ref, "refs/remotes/"+remote+"/"); ok && name != "HEAD" {
+				names[name] = true
+			}
+		}
+	}
+	return names
+}
+
+// Recover recreates the given branches at their recovered tips. Branches that
+// exist again are skipped.
+func Recover(ctx context.Context, r git.Runner, picks []Recoverable) (RestoreResult, error) {
+	branches := make([]JournalBranch, 0, len(picks))
+	for _, p := range picks {
+		branches = append(branches, JournalBranch{Name: p.Name, Ref: "refs/heads/" + p.Name, Tip: p.Tip})
+	}
+	return restoreBranches(ctx, r, branches)
+}
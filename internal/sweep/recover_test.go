@@ -0,0 +1,59 @@
+package sweep
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestHeadReflogTips(t *testing.T) {
+	at := func(s int64) time.Time { return time.Unix(s, 0) }
+	entries := []git.ReflogEntry{
+		{Commit: "m3", Time: at(500), Subject: "checkout: moving from cafe to main"},
+		{Commit: "cafe42", Time: at(450), Subject: "checkout: moving from main to cafe"},
+		{Commit: "m2", Time: at(400), Subject: "checkout: moving from feature/a to main"},
+		{Commit: "a2", Time: at(300), Subject: "commit: more work"},
+		{Commit: "a1", Time: at(200), Subject: "checkout: moving from main to feature/a"},
+		{Commit: "m1", Time: at(150), Subject: "checkout: moving from 0a1bcdef00000000000000000000000000000000 to main"},
+		{Commit: "0a1bcdef00000000000000000000000000000000", Time: at(100), Subject: "checkout: moving from old to 0a1bcdef"},
+		{Commit: "x0", Time: at(50), Subject: "commit (initial): init"},
+	}
+
+	got := make(map[string]Recoverable)
+	for _, c := range headReflogTips(entries) {
+		got[c.Name] = c
+	}
+	if c := got["feature/a"]; c.Tip != "a2" || !c.Seen.Equal(at(400)) {
+		t.Fatalf("feature/a: got %+v, want tip a2 seen at 400", c)
+	}
+	if c := got["main"]; c.Tip != "m3" {
+		t.Fatalf("main: got %+v, want newest tip m3", c)
+	}
+	if c := got["cafe"]; c.Tip != "cafe42" {
+		t.Fatalf("cafe: got %+v, want tip cafe42", c)
+	}
+	if c := got["old"]; c.Tip != "x0" {
+		t.Fatalf("old: got %+v, want tip x0", c)
+	}
+	for _, name := range []string{"0a1bcdef", "0a1bcdef00000000000000000000000000000000"} {
+		if _, ok := got[name]; ok {
+			t.Fatalf("detached checkout of %s should not be recoverable", name)
+		}
+	}
+}
+
+func TestRemoteBranchNames(t *testing.T) {
+	tips := map[string]string{
+		"refs/remotes/origin/HEAD":     "a",
+		"refs/remotes/origin/feature":  "a",
+		"refs/remotes/fork/team/topic": "b",
+		"refs/remotes/gone/leftover":   "c",
+	}
+	got := remoteBranchNames(tips, []string{"origin", "fork/team"})
+	want := map[string]bool{"feature": true, "topic": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,156 @@
+package sweep
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// RemovedRemote is a remote that is no longer configured but whose
+// remote-tracking refs are still present. Refs maps each full ref under
+// refs/remotes/<Name>/ to the object it pointed to when planning.
+type RemovedRemote struct {
+	Name string
+	Refs map[string]string
+}
+
+// markMissingRemotes flags branches whose branch.<name>.remote names a remote
+// that is no longer configured as gone: for-each-ref reports no upstream for
+// them, so they would otherwise never be considered. Values that look like a
+// URL or path rather than a remote name are left alone.
+func markMissingRemotes(branches []git.Branch, cfg map[string][]git.ConfigEntry, remotes []string) {
+	configured := make(map[string]bool, len(remotes))
+	for _, name := range remotes {
+		configured[name] = true
+	}
+	for i, b := range branches {
+		if b.UpstreamRef != "" {
+			continue
+		}
+		remote := ""
+		for _, e := range cfg[b.Name] {
+			if e.Key == "remote" {
+				remote = e.Value
+			}
+		}
+		if remote == "" || remote == "." || configured[remote] || strings.ContainsAny(remote, `:/\`) {
+			continue
+		}
+		branches[i].MissingRemote = remote
+		branches[i].IsGone = true
+	}
+}
+
+// removedRemotes groups the refs under refs/remotes that belong to no
+// configured remote by the remote name they were fetched for, sorted by name.
+// Refs that a fetch refspec still writes to (written, e.g. git-svn's
+// refs/remotes/git-svn or an ad-hoc remote.<name>.fetch destination) are
+// never listed. A leftover ref is attributed to the longest name in
+// branchRemotes (branch.<name>.remote values) that prefixes it, so a removed
+// remote named "team/a" is not reported as "team"; failing that, to its first
+// path component.
+func removedRemotes(ctx context.Context, r git.Runner, remotes, branchRemotes, written []string) ([]RemovedRemote, error) {
+	tips, err := git.RefTips(ctx, r, "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	byName := make(map[string]map[string]string)
+	for ref, tip := range tips {
+		if ownedByRemote(ref, remotes) || writtenByRefspec(ref, written) {
+			continue
+		}
+		name := leftoverRemoteName(ref, branchRemotes)
+		if name == "" {
+			continue
+		}
+		if byName[name] == nil {
+			byName[name] = make(map[string]string)
+		}
+		byName[name][ref] = tip
+	}
+	out := make([]RemovedRemote, 0, len(byName))
+	for name, refs := range byName {
+		out = append(out, RemovedRemote{Name: name, Refs: refs})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// leftoverRemoteName returns the remote a leftover ref was fetched for: the
+// longest of names whose namespace contains ref, or the first path component
+// below refs/remotes/. It returns "" for refs directly under refs/remotes/.
+func leftoverRemoteName(ref string, names []string) string {
+	best := ""
+	for _, name := range names {
+		if len(name) > len(best) && strings.HasPrefix(ref, "refs/remotes/"+name+"/") {
+			best = name
+		}
+	}
+	if best != "" {
+		return best
+	}
+	name, _, ok := strings.Cut(strings.TrimPrefix(ref, "refs/remotes/"), "/")
+	if !ok {
+		return ""
+	}
+	return name
+}
+
+// writtenByRefspec reports whether ref is the destination of one of the
+// fetch refspec destinations dsts, which are exact refs or globs such as
+// "refs/remotes/origin/*".
+func writtenByRefspec(ref string, dsts []string) bool {
+	for _, dst := range dsts {
+		if glob, _, isGlob := strings.Cut(dst, "*"); isGlob {
+			if strings.HasPrefix(ref, glob) {
+				return true
+			}
+		} else if ref == dst {
+			return true
+		}
+	}
+	return false
+}
+
+// ownedByRemote reports whether ref lies in the namespace of a configured
+// remote; remote names may themselves contain slashes.
+func ownedByRemote(ref string, remotes []string) bool {
+	for _, name := range remotes {
+		if strings.HasPrefix(ref, "refs/remotes/"+name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// branchRemoteNames returns the distinct branch.<name>.remote values of cfg
+// other than ".".
+func branchRemoteNames(cfg map[string][]git.ConfigEntry) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, entries := range cfg {
+		for _, e := range entries {
+			if e.Key == "remote" && e.Value != "." && !seen[e.Value] {
+				seen[e.Value] = true
+				names = append(names, e.Value)
+			}
+		}
+	}
+	return names
+}
+
+// PruneRemovedRemotes deletes the remote-tracking refs of removed remotes in
+// one `update-ref` transaction that verifies each ref still points where it
+// did when planning; if any moved, nothing is deleted.
+func PruneRemovedRemotes(ctx context.Context, r git.Runner, removed []RemovedRemote) error {
+	var dels []git.RefDeletion
+	for _, rm := range removed {
+		for ref, tip := range rm.Refs {
+			dels = append(dels, git.RefDeletion{Ref: ref, OldTip: tip})
+		}
+	}
+	sort.Slice(dels, func(i, j int) bool { return dels[i].Ref < dels[j].Ref })
+	return git.DeleteRefs(ctx, r, dels)
+}
@@ -0,0 +1,57 @@
+package sweep
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestMarkMissingRemotes(t *testing.T) {
+	branches := []git.Branch{
+		{Name: "tracked", UpstreamRef: "refs/remotes/origin/tracked"},
+		{Name: "old", Upstream: ""},
+		{Name: "stacked"},
+		{Name: "by-url"},
+	}
+	cfg := map[string][]git.ConfigEntry{
+		"old":     {{Key: "remote", Value: "upstream"}, {Key: "merge", Value: "refs/heads/old"}},
+		"stacked": {{Key: "remote", Value: "."}},
+		"by-url":  {{Key: "remote", Value: "https://example.com/repo.git"}},
+	}
+	markMissingRemotes(branches, cfg, []string{"origin"})
+	for _, b := range branches {
+		want := b.Name == "old"
+		if b.IsGone != want || (b.MissingRemote != "") != want {
+			t.Fatalf("unexpected flags for %s: %+v", b.Name, b)
+		}
+	}
+}
+
+func TestRemovedRemotes(t *testing.T) {
+	r := &policyRunner{out: map[string]string{
+		"for-each-ref --format=%(refname)%00%(objectname) refs/remotes": "" +
+			"refs/remotes/origin/main\x00aaa\n" +
+			"refs/remotes/fork/team/x\x00bbb\n" +
+			"refs/remotes/old/HEAD\x00ccc\n" +
+			"refs/remotes/old/main\x00ccc\n" +
+			"refs/remotes/team/a/topic\x00ddd\n" +
+			"refs/remotes/git-svn\x00eee\n" +
+			"refs/remotes/svn/trunk\x00fff\n",
+	}}
+	written := []string{"refs/remotes/origin/*", "refs/remotes/git-svn", "refs/remotes/svn/*"}
+	got, err := removedRemotes(context.Background(), r, []string{"origin", "fork/team"}, []string{"team", "team/a"}, written)
+	if err != nil {
+		t.Fatalf("removedRemotes: %v", err)
+	}
+	want := []RemovedRemote{{Name: "old", Refs: map[string]string{
+		"refs/remotes/old/HEAD": "ccc",
+		"refs/remotes/old/main": "ccc",
+	}}, {Name: "team/a", Refs: map[string]string{
+		"refs/remotes/team/a/topic": "ddd",
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+}
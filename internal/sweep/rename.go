@@ -0,0 +1,79 @@
+package sweep
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// DefaultRename describes a remote whose default branch was renamed, e.g.
+// master to main: refs/remotes/<Remote>/HEAD still points at the deleted From
+// while the remote's HEAD is now To. Branch is the local branch tracking the
+// old default, to be renamed to To; it is empty when a local To already
+// exists, in which case only the remote HEAD ref is updated.
+type DefaultRename struct {
+	Remote string
+	From   string
+	To     string
+	Branch string
+}
+
+// detectDefaultRename looks for a gone local branch tracking the branch that
+// refs/remotes/<remote>/HEAD points to, and asks the remote for its current
+// HEAD. It returns nil when nothing was renamed; failures to reach the remote
+// are treated the same, as detection is best effort.
+func detectDefaultRename(ctx context.Context, r git.Runner, remote string, branches []git.Branch) (*DefaultRename, error) {
+	if strings.TrimSpace(remote) == "" {
+		remote = "origin"
+	}
+	short, err := git.RemoteDefaultRef(ctx, r, remote)
+	if err != nil || short == "" {
+		return nil, nil
+	}
+	oldRef := "refs/remotes/" + short
+	var tracking *git.Branch
+	for i := range branches {
+		if branches[i].IsGone && branches[i].UpstreamRef == oldRef {
+			tracking = &branches[i]
+			break
+		}
+	}
+	if tracking == nil {
+		return nil, nil
+	}
+	from := strings.TrimPrefix(short, remote+"/")
+	to, err := git.RemoteHead(ctx, r, remote)
+	if err != nil || to == "" || to == from {
+		return nil, nil
+	}
+	remotes, err := git.RefTips(ctx, r, "refs/remotes/"+remote)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := remotes["refs/remotes/"+remote+"/"+to]; !ok {
+		return nil, nil
+	}
+	rename := &DefaultRename{Remote: remote, From: from, To: to, Branch: tracking.Name}
+	for _, b := range branches {
+		if b.Name == to {
+			rename.Branch = ""
+			break
+		}
+	}
+	return rename, nil
+}
+
+// applyDefaultRename renames the local branch, re-points its upstream to the
+// new default and updates refs/remotes/<remote>/HEAD.
+func applyDefaultRename(ctx context.Context, r git.Runner, rename DefaultRename) error {
+	if rename.Branch != "" {
+		if err := git.RenameBranch(ctx, r, rename.Branch, rename.To); err != nil {
+			return err
+		}
+		if err := git.SetUpstream(ctx, r, rename.To, rename.Remote+"/"+rename.To); err != nil {
+			return err
+		}
+	}
+	return git.SetRemoteHead(ctx, r, rename.Remote, rename.To)
+}
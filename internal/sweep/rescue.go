@@ -0,0 +1,84 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// RescuePrefix is the branch namespace on the rescue remote; rescued branches
+// are pushed to RescuePrefix + "<user>/<branch>".
+const RescuePrefix = "rescue/"
+
+// needsRescue reports whether b has commits that no remote-tracking ref holds.
+func needsRescue(ctx context.Context, r git.Runner, b git.Branch) bool {
+	n, err := git.UnpushedCount(ctx, r, b.Tip)
+	return err == nil && n > 0
+}
+
+// rescueBranches pushes the tips of branches to rescue/<user>/<name> on remote
+// in one push. It records each rescued branch in res.Rescued and marks the
+// others as failed so they are kept locally.
+func rescueBranches(ctx context.Context, r git.Runner, remote string, branches []git.Branch, res *Result) error {
+	if len(branches) == 0 {
+		return nil
+	}
+	user, err := rescueUser(ctx, r)
+	if err != nil {
+		return err
+	}
+	refspecs := make([]string, 0, len(branches))
+	for _, b := range branches {
+		refspecs = append(refspecs, b.Tip+":refs/heads/"+rescueBranch(user, b.Name))
+	}
+	accepted, pushErr := git.PushRefs(ctx, r, remote, refspecs)
+	for _, b := range branches {
+		name := rescueBranch(user, b.Name)
+		if !accepted["refs/heads/"+name] {
+			err := pushErr
+			if err == nil {
+				err = fmt.Errorf("%s did not accept %s", remote, name)
+			}
+			res.Failed[b.Name] = fmt.Errorf("rescue push failed; kept: %w", err)
+			delete(res.Methods, b.Name)
+			continue
+		}
+		res.Rescued[b.Name] = remote + "/" + name
+	}
+	return nil
+}
+
+// rescueBranch returns the rescue branch name for branch, e.g. "rescue/jane/feature/x".
+func rescueBranch(user, branch string) string {
+	return RescuePrefix + user + "/" + branch
+}
+
+// rescueUser names the rescue namespace after the local part of user.email,
+// falling back to user.name. Characters not allowed in a ref component are
+// replaced with '-'.
+func rescueUser(ctx context.Context, r git.Runner) (string, error) {
+	email, err := git.ConfigValue(ctx, r, "user.email")
+	if err != nil {
+		return "", err
+	}
+	user, _, _ := strings.Cut(email, "@")
+	if user == "" {
+		if user, err = git.ConfigValue(ctx, r, "user.name"); err != nil {
+			return "", err
+		}
+	}
+	user = strings.Trim(strings.Map(func(c rune) rune {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '-', c == '_':
+			return c
+		default:
+			return '-'
+		}
+	}, user), "-")
+	if user == "" {
+		return "", fmt.Errorf("cannot name the rescue namespace: set user.email or user.name")
+	}
+	return user, nil
+}
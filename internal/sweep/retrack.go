@@ -0,0 +1,118 @@
+package sweep
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// suggestUpstreams looks for gone candidates whose upstream was probably
+// renamed on the forge: a branch of remote points at the candidate's tip or,
+// failing that, exactly one contains it. Such candidates get
+// SuggestedUpstream set and are returned separately instead of being swept.
+// Candidates merged into the remote default branch are left to the sweep, as
+// newer branches would contain them too. Remote branches that cannot be a
+// rename target are ignored: the remote HEAD and default branch, protected
+// names, rescue branches and remote branches already tracked by another local
+// branch.
+func suggestUpstreams(ctx context.Context, r git.Runner, remote string, branches, candidates []git.Branch, protected []string) (kept, renamed []git.Branch, err error) {
+	if strings.TrimSpace(remote) == "" {
+		remote = "origin"
+	}
+	prefix := "refs/remotes/" + remote + "/"
+	excluded := map[string]bool{prefix + "HEAD": true}
+	defaultRef := ""
+	if short, err := git.RemoteDefaultRef(ctx, r, remote); err == nil && short != "" {
+		defaultRef = "refs/remotes/" + short
+		excluded[defaultRef] = true
+	}
+	for _, name := range protected {
+		excluded[prefix+name] = true
+	}
+	for _, b := range branches {
+		if b.UpstreamRef != "" {
+			excluded[b.UpstreamRef] = true
+		}
+	}
+	eligible := func(ref string) bool {
+		return !excluded[ref] && !strings.HasPrefix(ref, prefix+RescuePrefix)
+	}
+
+	var tips map[string]string
+	for _, b := range candidates {
+		if !b.IsGone || b.Tip == "" || !strings.HasPrefix(b.UpstreamRef, prefix) {
+			kept = append(kept, b)
+			continue
+		}
+		if defaultRef != "" {
+			if merged, _ := git.IsAncestor(ctx, r, b.Tip, defaultRef); merged {
+				kept = append(kept, b)
+				continue
+			}
+		}
+		if tips == nil {
+			if tips, err = git.RefTips(ctx, r, strings.TrimSuffix(prefix, "/")); err != nil {
+				return nil, nil, err
+			}
+		}
+		target, err := renameTarget(ctx, r, b.Tip, prefix, tips, eligible)
+		if err != nil {
+			return nil, nil, err
+		}
+		if target == "" {
+			kept = append(kept, b)
+			continue
+		}
+		b.SuggestedUpstream = strings.TrimPrefix(target, "refs/remotes/")
+		renamed = append(renamed, b)
+	}
+	return kept, renamed, nil
+}
+
+// renameTarget returns the eligible remote branch at tip, or the only eligible
+// one containing it; "" when there is none or the choice is ambiguous.
+func renameTarget(ctx context.Context, r git.Runner, tip, prefix string, tips map[string]string, eligible func(string) bool) (string, error) {
+	var exact []string
+	for ref, t := range tips {
+		if t == tip && eligible(ref) {
+			exact = append(exact, ref)
+		}
+	}
+	if len(exact) == 1 {
+		return exact[0], nil
+	}
+	if len(exact) > 1 {
+		return "", nil
+	}
+	containing, err := git.RefsContaining(ctx, r, tip, strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return "", err
+	}
+	target := ""
+	for _, ref := range containing {
+		if !eligible(ref) {
+			continue
+		}
+		if target != "" {
+			return "", nil
+		}
+		target = ref
+	}
+	return target, nil
+}
+
+// Retrack points each branch at its SuggestedUpstream and returns the
+// failures keyed by branch name.
+func Retrack(ctx context.Context, r git.Runner, branches []git.Branch) map[string]error {
+	failed := make(map[string]error)
+	for _, b := range branches {
+		if b.SuggestedUpstream == "" {
+			continue
+		}
+		if err := git.SetUpstream(ctx, r, b.Name, b.SuggestedUpstream); err != nil {
+			failed[b.Name] = err
+		}
+	}
+	return failed
+}
@@ -0,0 +1,88 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// stackDependents builds the local dependency graph: it maps each branch to
+// the branches stacked on it, i.e. tracking it with branch.<name>.remote set
+// to ".". Dependents are sorted by name.
+func stackDependents(branches []git.Branch) map[string][]string {
+	deps := make(map[string][]string)
+	for _, b := range branches {
+		if base, ok := strings.CutPrefix(b.UpstreamRef, "refs/heads/"); ok {
+			deps[base] = append(deps[base], b.Name)
+		}
+	}
+	for _, names := range deps {
+		sort.Strings(names)
+	}
+	return deps
+}
+
+// markOrphanedStacks flags branches whose local upstream no longer exists as
+// gone, like branches whose remote upstream was pruned.
+func markOrphanedStacks(branches []git.Branch) {
+	exists := make(map[string]bool, len(branches))
+	for _, b := range branches {
+		exists[b.Ref] = true
+	}
+	for i, b := range branches {
+		if strings.HasPrefix(b.UpstreamRef, "refs/heads/") && !exists[b.UpstreamRef] {
+			branches[i].IsGone = true
+		}
+	}
+}
+
+// candidateDependents returns, for each candidate that other branches are
+// stacked on, the dependents that are not candidates themselves and would be
+// orphaned by the sweep.
+func candidateDependents(branches, candidates []git.Branch) map[string][]string {
+	graph := stackDependents(branches)
+	swept := make(map[string]bool, len(candidates))
+	for _, c := range candidates {
+		swept[c.Name] = true
+	}
+	out := make(map[string][]string)
+	for _, c := range candidates {
+		for _, dep := range graph[c.Name] {
+			if !swept[dep] {
+				out[c.Name] = append(out[c.Name], dep)
+			}
+		}
+	}
+	return out
+}
+
+// repointDependents sets the upstream of branches stacked on deleted bases to
+// the remote default branch, recording them in res.Repointed. Failures are
+// recorded in res.Failed under the dependent's name.
+func repointDependents(ctx context.Context, r git.Runner, plan Plan, res *Result) {
+	var deps []string
+	for _, name := range res.Deleted {
+		deps = append(deps, plan.Dependents[name]...)
+	}
+	if len(deps) == 0 {
+		return
+	}
+	short, err := git.RemoteDefaultRef(ctx, r, plan.Remote)
+	if err != nil || short == "" {
+		err = fmt.Errorf("cannot resolve the default branch of %s", plan.Remote)
+	}
+	for _, dep := range deps {
+		if err != nil {
+			res.Failed[dep] = fmt.Errorf("re-pointing stacked branch: %w", err)
+			continue
+		}
+		if setErr := git.SetUpstream(ctx, r, dep, short); setErr != nil {
+			res.Failed[dep] = fmt.Errorf("re-pointing stacked branch: %w", setErr)
+			continue
+		}
+		res.Repointed[dep] = short
+	}
+}
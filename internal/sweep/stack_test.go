@@ -0,0 +1,36 @@
+package sweep
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestCandidateDependents(t *testing.T) {
+	branches := []git.Branch{
+		{Ref: "refs/heads/base", Name: "base", UpstreamRef: "refs/remotes/origin/base", IsGone: true},
+		{Ref: "refs/heads/top", Name: "top", UpstreamRef: "refs/heads/base"},
+		{Ref: "refs/heads/top2", Name: "top2", UpstreamRef: "refs/heads/base", IsGone: true},
+		{Ref: "refs/heads/other", Name: "other", UpstreamRef: "refs/remotes/origin/other"},
+	}
+	candidates := []git.Branch{branches[0], branches[2]}
+
+	got := candidateDependents(branches, candidates)
+	want := map[string][]string{"base": {"top"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestMarkOrphanedStacks(t *testing.T) {
+	branches := []git.Branch{
+		{Ref: "refs/heads/main", Name: "main"},
+		{Ref: "refs/heads/on-main", Name: "on-main", UpstreamRef: "refs/heads/main"},
+		{Ref: "refs/heads/orphan", Name: "orphan", UpstreamRef: "refs/heads/deleted"},
+	}
+	markOrphanedStacks(branches)
+	if branches[1].IsGone || !branches[2].IsGone {
+		t.Fatalf("unexpected gone flags: %+v", branches)
+	}
+}
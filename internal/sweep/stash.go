@@ -0,0 +1,70 @@
+package sweep
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// annotateStashes records on each branch the stash entries that reference it:
+// stashes made while the branch was checked out, stashes taken on the branch
+// tip, and stashes taken on a commit no remote has, which only the local
+// branches containing it can bring back. Deleting such a branch makes the
+// stash hard to reapply later. It runs one `for-each-ref --contains` per stash.
+func annotateStashes(ctx context.Context, r git.Runner, branches []git.Branch, stashes []git.Stash) error {
+	owners := make([]map[string]bool, len(stashes))
+	for i, s := range stashes {
+		var err error
+		if owners[i], err = unpushedStashOwners(ctx, r, s); err != nil {
+			return err
+		}
+	}
+	for i := range branches {
+		b := &branches[i]
+		b.Stashes = nil
+		for j, s := range stashes {
+			if s.Branch == b.Name || (b.Tip != "" && s.Base == b.Tip) || owners[j][b.Name] {
+				b.Stashes = append(b.Stashes, s.Ref)
+			}
+		}
+	}
+	return nil
+}
+
+// unpushedStashOwners returns the local branches containing the commit stash s
+// was taken on, or nil when a remote-tracking ref has that commit too: a base
+// shared with the remote (e.g. an old commit of main) says nothing about which
+// branch the stash belongs to.
+func unpushedStashOwners(ctx context.Context, r git.Runner, s git.Stash) (map[string]bool, error) {
+	if s.Base == "" {
+		return nil, nil
+	}
+	refs, err := git.RefsContaining(ctx, r, s.Base, "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, err
+	}
+	owners := make(map[string]bool)
+	for _, ref := range refs {
+		if strings.HasPrefix(ref, "refs/remotes/") {
+			return nil, nil
+		}
+		if name, ok := strings.CutPrefix(ref, "refs/heads/"); ok {
+			owners[name] = true
+		}
+	}
+	return owners, nil
+}
+
+// splitStashed separates the branches referenced by stash entries from the
+// others, so that the plan can list why they are kept.
+func splitStashed(branches []git.Branch) (kept, stashed []git.Branch) {
+	for _, b := range branches {
+		if len(b.Stashes) > 0 {
+			stashed = append(stashed, b)
+			continue
+		}
+		kept = append(kept, b)
+	}
+	return kept, stashed
+}
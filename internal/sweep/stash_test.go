@@ -0,0 +1,62 @@
+package sweep
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestAnnotateStashesAndProtect(t *testing.T) {
+	branches := []git.Branch{
+		{Name: "feature/named", IsGone: true, Tip: "111"},
+		{Name: "feature/based", IsGone: true, Tip: "222"},
+		{Name: "feature/built", IsGone: true, Tip: "444"},
+		{Name: "feature/free", IsGone: true, Tip: "333"},
+	}
+	contains := func(base string) string {
+		return "for-each-ref --contains " + base + " --format=%(refname) refs/heads refs/remotes"
+	}
+	r := &policyRunner{out: map[string]string{
+		contains("999"): "refs/heads/feature/named\nrefs/remotes/origin/feature/named\n",
+		contains("222"): "refs/heads/feature/based\nrefs/remotes/origin/main\n",
+		contains("555"): "refs/heads/feature/built\n",
+		// An old commit of main is in every branch but also on the remote.
+		contains("000"): "refs/heads/feature/based\nrefs/heads/feature/built\nrefs/heads/feature/free\nrefs/remotes/origin/main\n",
+	}}
+	err := annotateStashes(context.Background(), r, branches, []git.Stash{
+		{Ref: "stash@{0}", Base: "999", Branch: "feature/named"},
+		{Ref: "stash@{1}", Base: "222"},
+		{Ref: "stash@{2}", Base: "555"},
+		{Ref: "stash@{3}", Base: "000"},
+	})
+	if err != nil {
+		t.Fatalf("annotateStashes: %v", err)
+	}
+	if !reflect.DeepEqual(branches[0].Stashes, []string{"stash@{0}"}) {
+		t.Fatalf("feature/named: got %v", branches[0].Stashes)
+	}
+	if !reflect.DeepEqual(branches[1].Stashes, []string{"stash@{1}"}) {
+		t.Fatalf("feature/based: got %v", branches[1].Stashes)
+	}
+	if !reflect.DeepEqual(branches[2].Stashes, []string{"stash@{2}"}) {
+		t.Fatalf("feature/built: got %v", branches[2].Stashes)
+	}
+	if len(branches[3].Stashes) != 0 {
+		t.Fatalf("feature/free: got %v", branches[3].Stashes)
+	}
+
+	selected, err := SelectBranchesToDelete(branches, "main", "", FilterOptions{ProtectStashed: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(selected) != 1 || selected[0].Name != "feature/free" {
+		t.Fatalf("unexpected selection: %+v", selected)
+	}
+
+	kept, stashed := splitStashed(branches)
+	if len(kept) != 1 || kept[0].Name != "feature/free" || len(stashed) != 3 {
+		t.Fatalf("unexpected split: kept %+v, stashed %+v", kept, stashed)
+	}
+}
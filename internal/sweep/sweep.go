@@ -3,6 +3,9 @@
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/jmelosegui/git-sweep/internal/git"
 )
@@ -10,22 +13,78 @@
 // Options controls how sweep selects branches to delete. Deletion itself is not performed here.
 // Remote is used for fetch --prune and discovery scoping where applicable.
 // ExtraProtected extends the default protected names and environment-derived names.
+// ProtectRecent and StaleAfter use the HEAD reflog; see FilterOptions.
+// Mine and AuthorPattern limit candidates to branches whose unique commits were
+// authored by user.email or by identities matching the regex.
+// ProtectStashed keeps branches referenced by stash entries out of the plan;
+// when false they are still annotated with their stash entries.
+// ProtectUnrelated keeps branches that share no history with the remote
+// default branch (orphan branches such as gh-pages) out of the plan; when
+// false they are still marked Unrelated.
+// PruneConfig also looks for `branch.<name>` config sections of branches that
+// no longer exist (see Plan.OrphanedConfigs).
+// PruneRemotes also looks for remote-tracking refs of remotes that are no
+// longer configured (see Plan.RemovedRemotes).
+// Switch lets a current branch that would otherwise be a candidate be swept by
+// switching to the remote default branch first (see Plan.SwitchTo).
 type Options struct {
-	Remote          string
-	IncludePattern  string
-	ExcludePattern  string
-	ExtraProtected  []string
-	ProtectCurrent  bool
-	ProtectUpstream bool
+	Remote           string
+	IncludePattern   string
+	ExcludePattern   string
+	ExtraProtected   []string
+	ProtectCurrent   bool
+	ProtectUpstream  bool
+	ProtectRecent    time.Duration
+	StaleAfter       time.Duration
+	Mine             bool
+	AuthorPattern    string
+	ProtectStashed   bool
+	ProtectUnrelated bool
+	PruneConfig      bool
+	PruneRemotes     bool
+	Switch           bool
 }
 
 // Plan contains the branches selected for deletion along with context information.
+// Operation is set when a rebase, merge or similar was in progress while planning;
+// such a plan protects the branch involved and cannot be executed.
+// Unborn is set for a repository without commits; the plan is then empty.
+// HEAD may also be unborn on an orphan branch of a repository that has other
+// branches; HeadCommit is then empty and the orphan is the current branch.
+// Detached is set when HEAD is detached at HeadCommit; CurrentBranch is then
+// empty and branches pointing at HeadCommit are protected.
+// SwitchTo is the default branch to check out before sweeping when
+// Options.Switch made the current branch a candidate; SwitchRefused says why
+// the current branch was kept instead.
+// DefaultRename is set when the remote's default branch was renamed; the local
+// branch tracking the old default is then migrated rather than swept.
+// Renamed holds gone branches whose upstream appears to have been renamed;
+// each carries a SuggestedUpstream and is kept out of Candidates.
+// Dependents maps candidates to the branches stacked on them (tracking them
+// as a local upstream) that the sweep would orphan.
+// Unrelated names branches kept out of Candidates because they share no
+// history with the remote default branch (see Options.ProtectUnrelated).
+// Stashed holds branches that would be candidates but are kept because stash
+// entries reference them (see Options.ProtectStashed).
 type Plan struct {
 	RepoRoot        string
 	Remote          string
 	CurrentBranch   string
 	CurrentUpstream string
+	HeadCommit      string
+	Detached        bool
+	Unborn          bool
+	Operation       git.Operation
 	Candidates      []git.Branch
+	SwitchTo        string
+	SwitchRefused   string
+	DefaultRename   *DefaultRename
+	Renamed         []git.Branch
+	Dependents      map[string][]string
+	Unrelated       []string
+	Stashed         []git.Branch
+	OrphanedConfigs []string
+	RemovedRemotes  []RemovedRemote
 }
 
 // BuildPlan discovers gone branches and filters them according to Options and protections.
@@ -46,6 +105,26 @@ func BuildPlan(ctx context.Context, r git.Runner, opts Options) (Plan, error) {
 	}
 	plan.RepoRoot = root
 
+	head, err := git.HeadCommit(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	if head == "" {
+		// HEAD is unborn: either nothing has been committed yet, or the
+		// current branch was just created with `checkout --orphan` and the
+		// other branches are still there to sweep.
+		heads, err := git.RefTips(ctx, r, "refs/heads")
+		if err != nil {
+			return plan, err
+		}
+		if len(heads) == 0 {
+			plan.Unborn = true
+			plan.Remote = opts.Remote
+			return plan, nil
+		}
+	}
+	plan.HeadCommit = head
+
 	// Fetch prune for the selected remote
 	if err := git.FetchPrune(ctx, r, opts.Remote); err != nil {
 		return plan, err
@@ -57,32 +136,190 @@ func BuildPlan(ctx context.Context, r git.Runner, opts Options) (Plan, error) {
 	if err != nil {
 		return plan, err
 	}
+	markOrphanedStacks(branches)
+
+	remotes, err := git.Remotes(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	branchConfig, err := git.BranchConfig(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	markMissingRemotes(branches, branchConfig, remotes)
+	if opts.PruneRemotes {
+		written, err := git.FetchDestinations(ctx, r)
+		if err != nil {
+			return plan, err
+		}
+		if plan.RemovedRemotes, err = removedRemotes(ctx, r, remotes, branchRemoteNames(branchConfig), written); err != nil {
+			return plan, err
+		}
+	}
+
+	rename, err := detectDefaultRename(ctx, r, opts.Remote, branches)
+	if err != nil {
+		return plan, err
+	}
+	plan.DefaultRename = rename
 
 	// Protections
 	current, err := git.CurrentBranch(ctx, r)
 	if err != nil {
 		return plan, err
 	}
-	upstream, _ := git.BranchUpstream(ctx, r, current) // no upstream is not an error
+	upstream, upstreamRef := "", ""
+	var currentBranch git.Branch
+	var headProtected []string
+	if current == "HEAD" {
+		// Detached HEAD: there is no current branch, but any branch at the
+		// detached commit is what the user is looking at.
+		plan.Detached = true
+		current = ""
+		for _, b := range branches {
+			if b.Tip == head {
+				headProtected = append(headProtected, b.Name)
+			}
+		}
+	} else {
+		for _, b := range branches {
+			if b.Name == current {
+				currentBranch = b
+				upstream, upstreamRef = b.Upstream, b.UpstreamRef
+				break
+			}
+		}
+	}
 
 	plan.CurrentBranch = current
 	plan.CurrentUpstream = upstream
 
+	op, err := git.InProgressOperation(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	plan.Operation = op
+
+	if opts.ProtectRecent > 0 || opts.StaleAfter > 0 {
+		checkouts, err := git.LastCheckouts(ctx, r)
+		if err != nil {
+			return plan, err
+		}
+		for i := range branches {
+			branches[i].LastCheckout = checkouts[branches[i].Name]
+		}
+	}
+
+	stashes, err := git.ListStashes(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	if err := annotateStashes(ctx, r, branches, stashes); err != nil {
+		return plan, err
+	}
+
 	baseProtected := git.DefaultProtectedNames()
 	envProtected := ProtectedNamesFromEnvVar()
 	protected := MergeProtectedNames(baseProtected, envProtected)
 	protected = MergeProtectedNames(protected, opts.ExtraProtected)
+	protected = MergeProtectedNames(protected, []string{op.Branch})
+	protected = MergeProtectedNames(protected, headProtected)
+	if rename != nil && rename.Branch != "" {
+		protected = MergeProtectedNames(protected, []string{rename.Branch})
+	}
+	// A branch aliased by a symbolic branch would leave the alias dangling.
+	for _, b := range branches {
+		if target, ok := strings.CutPrefix(b.SymrefTarget, "refs/heads/"); ok {
+			protected = MergeProtectedNames(protected, []string{target})
+		}
+	}
+
+	// Branches checked out in other worktrees are as current as ours, and so
+	// are branches being rebased there, which those worktrees show as detached.
+	worktreeRefs, err := git.WorktreeBranches(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	opBranches, err := git.WorktreeOperationBranches(ctx, r)
+	if err != nil {
+		return plan, err
+	}
+	protected = MergeProtectedNames(protected, opBranches)
+	// The current branch is left to ProtectCurrent so that Switch can lift it.
+	for _, ref := range worktreeRefs {
+		if name := strings.TrimPrefix(ref, "refs/heads/"); name != current {
+			protected = MergeProtectedNames(protected, []string{name})
+		}
+	}
 
-	selected, err := SelectBranchesToDelete(branches, current, upstream, FilterOptions{
+	filter := FilterOptions{
 		IncludePattern:  opts.IncludePattern,
 		ExcludePattern:  opts.ExcludePattern,
 		ProtectedNames:  protected,
 		ProtectCurrent:  opts.ProtectCurrent,
 		ProtectUpstream: opts.ProtectUpstream,
-	})
+		ProtectRecent:   opts.ProtectRecent,
+		StaleAfter:      opts.StaleAfter,
+		ProtectStashed:  opts.ProtectStashed,
+	}
+	// Stashed branches are set aside after filtering so the plan can list them.
+	unstashed := filter
+	unstashed.ProtectStashed = false
+	selected, err := SelectBranchesToDelete(branches, current, upstreamRef, unstashed)
 	if err != nil {
 		return plan, err
 	}
+	if opts.ProtectStashed {
+		selected, plan.Stashed = splitStashed(selected)
+	}
+
+	if opts.Switch && current != "" && op.Name == "" {
+		plan.SwitchTo, plan.SwitchRefused, err = planSwitch(ctx, r, opts.Remote, currentBranch, worktreeRefs, filter)
+		if err != nil {
+			return plan, err
+		}
+		if plan.SwitchTo != "" {
+			selected = append(selected, currentBranch)
+			sort.Slice(selected, func(i, j int) bool { return selected[i].Name < selected[j].Name })
+		}
+	}
+
+	matchers, err := authorMatchers(ctx, r, opts)
+	if err != nil {
+		return plan, err
+	}
+	selected, err = filterByAuthor(ctx, r, selected, matchers)
+	if err != nil {
+		return plan, err
+	}
+	selected, plan.Unrelated, err = filterUnrelated(ctx, r, unrelatedBase(ctx, r, opts.Remote, head), selected, opts.ProtectUnrelated)
+	if err != nil {
+		return plan, err
+	}
+	selected, plan.Renamed, err = suggestUpstreams(ctx, r, opts.Remote, branches, selected, protected)
+	if err != nil {
+		return plan, err
+	}
+	if plan.SwitchTo != "" && !containsBranch(selected, current) {
+		// The author or history filters dropped the current branch, or it
+		// is to be re-tracked; stay on it.
+		plan.SwitchTo = ""
+	}
+	if opts.PruneConfig {
+		if plan.OrphanedConfigs, err = orphanedBranchConfigs(ctx, r, branchConfig); err != nil {
+			return plan, err
+		}
+	}
 	plan.Candidates = selected
+	plan.Dependents = candidateDependents(branches, selected)
 	return plan, nil
 }
+
+func containsBranch(branches []git.Branch, name string) bool {
+	for _, b := range branches {
+		if b.Name == name {
+			return true
+		}
+	}
+	return false
+}
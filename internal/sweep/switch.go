@@ -0,0 +1,97 @@
+package sweep
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// planSwitch decides whether the sweep can switch off the current branch cur
+// and include it. cur must pass the same filters as any other candidate, the
+// remote must have a default branch other than cur that is not checked out in
+// another worktree, and the worktree must be clean. It returns the branch to
+// switch to, or why switching was refused; both are empty when cur is not a
+// candidate at all.
+func planSwitch(ctx context.Context, r git.Runner, remote string, cur git.Branch, worktreeRefs []string, filter FilterOptions) (target, refused string, err error) {
+	filter.ProtectCurrent = false
+	selected, err := SelectBranchesToDelete([]git.Branch{cur}, "", "", filter)
+	if err != nil || len(selected) == 0 {
+		return "", "", err
+	}
+	if strings.TrimSpace(remote) == "" {
+		remote = "origin"
+	}
+	short, err := git.RemoteDefaultRef(ctx, r, remote)
+	if err != nil || short == "" {
+		return "", fmt.Sprintf("cannot resolve the default branch of %s (try 'git remote set-head %s --auto')", remote, remote), nil
+	}
+	target = strings.TrimPrefix(short, remote+"/")
+	if target == cur.Name {
+		return "", "it is the default branch", nil
+	}
+	for _, ref := range worktreeRefs {
+		if ref == "refs/heads/"+target {
+			return "", fmt.Sprintf("'%s' is checked out in another worktree", target), nil
+		}
+	}
+	clean, err := git.WorktreeClean(ctx, r)
+	if err != nil {
+		return "", "", err
+	}
+	if !clean {
+		return "", "the worktree has uncommitted changes", nil
+	}
+	return target, "", nil
+}
+
+// switchToDefault checks out plan.SwitchTo, creating it from the remote
+// default branch when it does not exist locally, and fast-forwards it. A
+// failed fast-forward leaves the branch where it was and is returned as ffErr;
+// err is set when the switch itself did not happen.
+func switchToDefault(ctx context.Context, r git.Runner, plan Plan) (ffErr error, err error) {
+	clean, err := git.WorktreeClean(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if !clean {
+		return nil, fmt.Errorf("the worktree has uncommitted changes")
+	}
+	remote := plan.Remote
+	if strings.TrimSpace(remote) == "" {
+		remote = "origin"
+	}
+	short, err := git.RemoteDefaultRef(ctx, r, remote)
+	if err != nil {
+		return nil, err
+	}
+	remoteRef := "refs/remotes/" + short
+	heads, err := git.RefTips(ctx, r, "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := heads["refs/heads/"+plan.SwitchTo]; !ok {
+		return nil, git.CheckoutTracking(ctx, r, plan.SwitchTo, remoteRef)
+	}
+	if err := git.Checkout(ctx, r, plan.SwitchTo); err != nil {
+		return nil, err
+	}
+	return git.FastForward(ctx, r, remoteRef), nil
+}
+
+// switchBackUnlessDeleted checks out prev again when the sweep switched off it
+// but did not delete it, e.g. because the deletion policy refused it or a
+// later step failed, so the user is not left on another branch.
+func switchBackUnlessDeleted(ctx context.Context, r git.Runner, prev string, res *Result) error {
+	for _, name := range res.Deleted {
+		if name == prev {
+			return nil
+		}
+	}
+	if err := git.Checkout(ctx, r, prev); err != nil {
+		return fmt.Errorf("switching back to %s: %w", prev, err)
+	}
+	res.SwitchedTo, res.FastForwardErr = "", nil
+	return nil
+}
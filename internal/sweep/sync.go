@@ -0,0 +1,119 @@
+package sweep
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// SyncedBranch is a branch fast-forwarded (or, in a dry run, to be
+// fast-forwarded) from From to its upstream at To.
+type SyncedBranch struct {
+	Name     string
+	Upstream string
+	From     string
+	To       string
+}
+
+// SyncResult reports what Sync did with every branch that is not up to date
+// with its upstream. Skipped maps branch names to the reason they were left
+// alone, e.g. being ahead of the upstream or checked out in another worktree.
+type SyncResult struct {
+	Updated  []SyncedBranch
+	Diverged []string
+	Skipped  map[string]string
+}
+
+// Sync fast-forwards every local branch that is strictly behind its upstream,
+// as fetched by BuildPlan. Branches that are not checked out are moved in one
+// verified `update-ref` transaction; the current branch is updated with
+// `merge --ff-only` so the worktree follows. Branches checked out in other
+// worktrees are skipped. With dryRun nothing changes and Updated lists the
+// branches that would move.
+func Sync(ctx context.Context, r git.Runner, dryRun bool) (SyncResult, error) {
+	res := SyncResult{Skipped: make(map[string]string)}
+	branches, err := git.ListLocalBranches(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	current, err := git.CurrentBranch(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	worktreeRefs, err := git.WorktreeBranches(ctx, r)
+	if err != nil {
+		return res, err
+	}
+	checkedOut := make(map[string]bool, len(worktreeRefs))
+	for _, ref := range worktreeRefs {
+		checkedOut[ref] = true
+	}
+	tips, err := git.RefTips(ctx, r, "refs/remotes")
+	if err != nil {
+		return res, err
+	}
+	for _, b := range branches {
+		tips[b.Ref] = b.Tip
+	}
+
+	var updates []git.RefUpdate
+	var pending []SyncedBranch
+	var currentSync *SyncedBranch
+	for _, b := range branches {
+		if b.UpstreamRef == "" || b.IsGone {
+			continue
+		}
+		upTip := tips[b.UpstreamRef]
+		if upTip == "" || upTip == b.Tip {
+			continue
+		}
+		if behind, _ := git.IsAncestor(ctx, r, b.Tip, upTip); !behind {
+			if ahead, _ := git.IsAncestor(ctx, r, upTip, b.Tip); ahead {
+				res.Skipped[b.Name] = "ahead of " + b.Upstream
+			} else {
+				res.Diverged = append(res.Diverged, b.Name)
+			}
+			continue
+		}
+		s := SyncedBranch{Name: b.Name, Upstream: b.Upstream, From: b.Tip, To: upTip}
+		switch {
+		case b.Name == current:
+			currentSync = &s
+		case checkedOut[b.Ref]:
+			res.Skipped[b.Name] = "checked out in another worktree"
+		default:
+			updates = append(updates, git.RefUpdate{Ref: b.Ref, OldTip: b.Tip, NewTip: upTip})
+			pending = append(pending, s)
+		}
+	}
+
+	if dryRun {
+		res.Updated = append(res.Updated, pending...)
+		if currentSync != nil {
+			res.Updated = append(res.Updated, *currentSync)
+		}
+		return res, nil
+	}
+	if err := git.UpdateRefs(ctx, r, updates); err != nil {
+		for _, s := range pending {
+			res.Skipped[s.Name] = errorReason(err)
+		}
+	} else {
+		res.Updated = append(res.Updated, pending...)
+	}
+	if currentSync != nil {
+		if err := git.FastForward(ctx, r, currentSync.To); err != nil {
+			res.Skipped[currentSync.Name] = errorReason(err)
+		} else {
+			res.Updated = append(res.Updated, *currentSync)
+		}
+	}
+	return res, nil
+}
+
+// errorReason renders err as a one-line skip reason.
+func errorReason(err error) string {
+	msg, _, _ := strings.Cut(err.Error(), "\n")
+	return msg
+}
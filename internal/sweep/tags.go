@@ -0,0 +1,87 @@
+package sweep
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// TagOptions controls which local tags `git sweep tags` selects. Remote is
+// asked for its tag list; IncludePattern, ExcludePattern and ExtraProtected
+// behave as in Options and apply to tag names.
+type TagOptions struct {
+	Remote         string
+	IncludePattern string
+	ExcludePattern string
+	ExtraProtected []string
+}
+
+// Tag is a local tag; Object is what refs/tags/<Name> pointed to when planning.
+type Tag struct {
+	Name   string
+	Ref    string
+	Object string
+}
+
+// TagPlan lists the local tags that do not exist on Remote.
+type TagPlan struct {
+	Remote     string
+	Candidates []Tag
+}
+
+// BuildTagPlan selects local tags missing from the remote's tag list. Tags
+// protected by name (the default protected names, GIT_SWEEP_PROTECTED and
+// ExtraProtected) and the archive/ tags written by --archive=tag are never
+// selected. Failing to reach the remote is an error rather than an empty
+// remote, which would select every tag.
+func BuildTagPlan(ctx context.Context, r git.Runner, opts TagOptions) (TagPlan, error) {
+	if strings.TrimSpace(opts.Remote) == "" {
+		opts.Remote = "origin"
+	}
+	plan := TagPlan{Remote: opts.Remote}
+	includeRe, excludeRe, err := compilePatterns(opts.IncludePattern, opts.ExcludePattern)
+	if err != nil {
+		return plan, err
+	}
+	remoteTags, err := git.RemoteTags(ctx, r, opts.Remote)
+	if err != nil {
+		return plan, err
+	}
+	local, err := git.RefTips(ctx, r, "refs/tags")
+	if err != nil {
+		return plan, err
+	}
+
+	protected := MergeProtectedNames(git.DefaultProtectedNames(), ProtectedNamesFromEnvVar())
+	protected = MergeProtectedNames(protected, opts.ExtraProtected)
+	isProtected := make(map[string]bool, len(protected))
+	for _, n := range protected {
+		isProtected[n] = true
+	}
+
+	for ref, object := range local {
+		name := strings.TrimPrefix(ref, "refs/tags/")
+		if remoteTags[ref] || isProtected[name] || strings.HasPrefix(ref, ArchiveTagPrefix) {
+			continue
+		}
+		if !matchesPatterns(name, includeRe, excludeRe) {
+			continue
+		}
+		plan.Candidates = append(plan.Candidates, Tag{Name: name, Ref: ref, Object: object})
+	}
+	sort.Slice(plan.Candidates, func(i, j int) bool { return plan.Candidates[i].Name < plan.Candidates[j].Name })
+	return plan, nil
+}
+
+// DeleteTags deletes the planned tags in one `update-ref` transaction that
+// verifies each still points at its planned object; if any moved, nothing is
+// deleted.
+func DeleteTags(ctx context.Context, r git.Runner, plan TagPlan) error {
+	dels := make([]git.RefDeletion, 0, len(plan.Candidates))
+	for _, t := range plan.Candidates {
+		dels = append(dels, git.RefDeletion{Ref: t.Ref, OldTip: t.Object})
+	}
+	return git.DeleteRefs(ctx, r, dels)
+}
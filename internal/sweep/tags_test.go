@@ -0,0 +1,34 @@
+package sweep
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBuildTagPlan(t *testing.T) {
+	t.Setenv(ProtectedEnvVar, "keep")
+	r := &policyRunner{out: map[string]string{
+		"ls-remote --tags --refs origin": "aaa\trefs/tags/v1.0\n",
+		"for-each-ref --format=%(refname)%00%(objectname) refs/tags": "" +
+			"refs/tags/v1.0\x00aaa\n" +
+			"refs/tags/v1.1-local\x00bbb\n" +
+			"refs/tags/wip/spike\x00ccc\n" +
+			"refs/tags/keep\x00ddd\n" +
+			"refs/tags/archive/feature/x\x00eee\n",
+	}}
+
+	plan, err := BuildTagPlan(context.Background(), r, TagOptions{Remote: "origin", ExcludePattern: "^wip/"})
+	if err != nil {
+		t.Fatalf("BuildTagPlan: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "v1.1-local" || plan.Candidates[0].Object != "bbb" {
+		t.Fatalf("unexpected candidates: %+v", plan.Candidates)
+	}
+}
+
+func TestBuildTagPlan_RemoteUnreachable(t *testing.T) {
+	r := &policyRunner{fail: map[string]bool{"ls-remote --tags --refs origin": true}}
+	if _, err := BuildTagPlan(context.Background(), r, TagOptions{Remote: "origin"}); err == nil {
+		t.Fatal("expected an error when the remote cannot be listed")
+	}
+}
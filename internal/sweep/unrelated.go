@@ -0,0 +1,46 @@
+package sweep
+
+import (
+	"context"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+// unrelatedBase returns the ref orphan detection compares against: the remote
+// default branch, or HEAD when refs/remotes/<remote>/HEAD is not set. It
+// returns "" when neither exists (head is empty on an unborn branch).
+func unrelatedBase(ctx context.Context, r git.Runner, remote, head string) string {
+	short, err := git.RemoteDefaultRef(ctx, r, remote)
+	if err != nil || short == "" {
+		if head == "" {
+			return ""
+		}
+		return "HEAD"
+	}
+	return "refs/remotes/" + short
+}
+
+// filterUnrelated marks candidates that share no history with base, such as
+// gh-pages or vendor-import branches created with --orphan. When protect is
+// set they are dropped and returned separately; otherwise they stay candidates
+// and only carry the Unrelated note. Without a base nothing is filtered.
+func filterUnrelated(ctx context.Context, r git.Runner, base string, branches []git.Branch, protect bool) (kept []git.Branch, unrelated []string, err error) {
+	if base == "" {
+		return branches, nil, nil
+	}
+	for _, b := range branches {
+		related, err := git.HasMergeBase(ctx, r, base, b.Ref)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !related {
+			b.Unrelated = true
+			if protect {
+				unrelated = append(unrelated, b.Name)
+				continue
+			}
+		}
+		kept = append(kept, b)
+	}
+	return kept, unrelated, nil
+}
@@ -0,0 +1,37 @@
+package sweep
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+)
+
+func TestFilterUnrelated(t *testing.T) {
+	const base = "refs/remotes/origin/main"
+	branches := []git.Branch{
+		{Ref: "refs/heads/feature", Name: "feature", IsGone: true},
+		{Ref: "refs/heads/gh-pages", Name: "gh-pages", IsGone: true},
+	}
+	r := &policyRunner{fail: map[string]bool{"merge-base " + base + " refs/heads/gh-pages": true}}
+
+	kept, unrelated, err := filterUnrelated(context.Background(), r, base, branches, true)
+	if err != nil {
+		t.Fatalf("filterUnrelated: %v", err)
+	}
+	if len(kept) != 1 || kept[0].Name != "feature" || kept[0].Unrelated {
+		t.Fatalf("unexpected kept branches: %+v", kept)
+	}
+	if !reflect.DeepEqual(unrelated, []string{"gh-pages"}) {
+		t.Fatalf("unexpected unrelated branches: %v", unrelated)
+	}
+
+	kept, unrelated, err = filterUnrelated(context.Background(), r, base, branches, false)
+	if err != nil {
+		t.Fatalf("filterUnrelated: %v", err)
+	}
+	if len(kept) != 2 || !kept[1].Unrelated || len(unrelated) != 0 {
+		t.Fatalf("expected gh-pages to be kept as a marked candidate: %+v, %v", kept, unrelated)
+	}
+}
@@ -7,12 +7,12 @@
 	"strings"
 )
 
-// ConfirmDeletion prompts the user to confirm deletion of n branches.
+// Confirm asks a yes/no question.
 // Returns true when the user types "y" or "yes" (case-insensitive). Any
 // other answer prints "not a yes -- aborting." and returns false so the
 // user knows the input was treated as a decline rather than silently
 // dismissed. If stdin is not a terminal, it returns false with nil error.
-func ConfirmDeletion(n int) (bool, error) {
+func Confirm(question string) (bool, error) {
 	// Detect non-interactive stdin
 	info, err := os.Stdin.Stat()
 	if err != nil {
@@ -23,7 +23,7 @@ func ConfirmDeletion(n int) (bool, error) {
 	}
 
 	reader := bufio.NewReader(os.Stdin)
-	if _, err := fmt.Fprintf(os.Stdout, "Proceed with deleting %d branch(es)? [y/N]: ", n); err != nil {
+	if _, err := fmt.Fprintf(os.Stdout, "%s [y/N]: ", question); err != nil {
 		return false, err
 	}
 	line, err := reader.ReadString('\n')
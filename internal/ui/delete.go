@@ -3,23 +3,67 @@
 import (
 	"fmt"
 	"os"
+	"sort"
 
+	"github.com/jmelosegui/git-sweep/internal/git"
 	"github.com/jmelosegui/git-sweep/internal/sweep"
 )
 
 // PrintDeletionResult prints a summary of deletions.
 func PrintDeletionResult(res sweep.Result) error {
 	w := os.Stdout
+	if rn := res.DefaultRenamed; rn != nil {
+		if rn.Branch != "" {
+			if _, err := fmt.Fprintf(w, "Renamed '%s' to '%s', now tracking '%s/%s'\n", rn.Branch, rn.To, rn.Remote, rn.To); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintf(w, "Set %s/HEAD to %s/%s\n", rn.Remote, rn.Remote, rn.To); err != nil {
+			return err
+		}
+	}
+	if res.SwitchedTo != "" {
+		if _, err := fmt.Fprintf(w, "Switched to branch '%s'\n", res.SwitchedTo); err != nil {
+			return err
+		}
+		if res.FastForwardErr != nil {
+			if _, err := fmt.Fprintf(w, "warning: could not fast-forward '%s': %v\n", res.SwitchedTo, res.FastForwardErr); err != nil {
+				return err
+			}
+		}
+	}
 	if len(res.Deleted) > 0 {
-		if _, err := fmt.Fprintf(w, "Deleted %d branch(es):\n", len(res.Deleted)); err != nil {
+		verb := "Deleted"
+		if len(res.Archived) > 0 {
+			verb = "Archived"
+		}
+		if _, err := fmt.Fprintf(w, "%s %d branch(es):\n", verb, len(res.Deleted)); err != nil {
 			return err
 		}
 		for _, name := range res.Deleted {
-			if _, err := fmt.Fprintf(w, "  - %s\n", name); err != nil {
+			target := ""
+			if ref, ok := res.Archived[name]; ok {
+				target = " -> " + ref
+			}
+			reason := describeMethod(res.Methods[name])
+			if ref, ok := res.Rescued[name]; ok {
+				reason = "rescued to " + ref
+			}
+			if _, err := fmt.Fprintf(w, "  - %s%s (was %s, %s)\n", name, target, git.ShortSHA(res.Tips[name]), reason); err != nil {
 				return err
 			}
 		}
 	}
+	repointed := make([]string, 0, len(res.Repointed))
+	for name := range res.Repointed {
+		repointed = append(repointed, name)
+	}
+	sort.Strings(repointed)
+	for _, name := range repointed {
+		if _, err := fmt.Fprintf(w, "Stacked branch '%s' now tracks '%s'\n", name, res.Repointed[name]); err != nil {
+			return err
+		}
+	}
 	if len(res.Failed) > 0 {
 		if _, err := fmt.Fprintf(w, "Failures (%d):\n", len(res.Failed)); err != nil {
 			return err
@@ -32,3 +76,21 @@ func PrintDeletionResult(res sweep.Result) error {
 	}
 	return nil
 }
+
+// describeMethod explains why a branch was allowed to be deleted.
+func describeMethod(m sweep.DeleteMethod) string {
+	switch m {
+	case sweep.MethodSafe:
+		return "merged"
+	case sweep.MethodMerged:
+		return "merged into the default branch"
+	case sweep.MethodPushed:
+		return "no unpushed commits"
+	case sweep.MethodRescued:
+		return "rescued"
+	case sweep.MethodForced:
+		return "--force"
+	default:
+		return "deleted"
+	}
+}
@@ -4,7 +4,9 @@
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/jmelosegui/git-sweep/internal/git"
 	"github.com/jmelosegui/git-sweep/internal/sweep"
 )
 
@@ -22,7 +24,17 @@ func PrintPlan(plan sweep.Plan, opts Options) (int, error) {
 		return 0, enc.Encode(plan)
 	}
 	w := os.Stdout
-	if _, err := fmt.Fprintf(w, "On branch %s\n", plan.CurrentBranch); err != nil {
+	if plan.Unborn {
+		if _, err := fmt.Fprintln(w, "No commits yet; nothing to sweep."); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if plan.Detached {
+		if _, err := fmt.Fprintf(w, "HEAD detached at %s\n", git.ShortSHA(plan.HeadCommit)); err != nil {
+			return 0, err
+		}
+	} else if _, err := fmt.Fprintf(w, "On branch %s\n", plan.CurrentBranch); err != nil {
 		return 0, err
 	}
 	if plan.CurrentUpstream != "" {
@@ -38,22 +50,164 @@ func PrintPlan(plan sweep.Plan, opts Options) (int, error) {
 		}
 	}
 
+	if plan.Operation.Name != "" {
+		msg := fmt.Sprintf("git %s in progress", plan.Operation.Name)
+		if plan.Operation.Branch != "" {
+			msg += fmt.Sprintf(" on '%s'", plan.Operation.Branch)
+		}
+		if _, err := fmt.Fprintf(w, "%s; finish or abort it before sweeping.\n\n", msg); err != nil {
+			return 0, err
+		}
+	}
+
+	if rn := plan.DefaultRename; rn != nil {
+		if _, err := fmt.Fprintf(w, "The default branch of %s was renamed from '%s' to '%s'.\n", rn.Remote, rn.From, rn.To); err != nil {
+			return 0, err
+		}
+		if rn.Branch != "" {
+			if _, err := fmt.Fprintf(w, "Will rename '%s' to '%s', track '%s/%s' and update %s/HEAD instead of sweeping it.\n\n", rn.Branch, rn.To, rn.Remote, rn.To, rn.Remote); err != nil {
+				return 0, err
+			}
+		} else if _, err := fmt.Fprintf(w, "Will update %s/HEAD to '%s/%s'.\n\n", rn.Remote, rn.Remote, rn.To); err != nil {
+			return 0, err
+		}
+	}
+
+	if plan.SwitchTo != "" {
+		if _, err := fmt.Fprintf(w, "Will switch to '%s' and sweep '%s'.\n\n", plan.SwitchTo, plan.CurrentBranch); err != nil {
+			return 0, err
+		}
+	} else if plan.SwitchRefused != "" {
+		if _, err := fmt.Fprintf(w, "Not switching off '%s': %s.\n\n", plan.CurrentBranch, plan.SwitchRefused); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(plan.Renamed) > 0 {
+		if _, err := fmt.Fprintln(w, "The upstream of these branches appears to have been renamed (--retrack to follow it):"); err != nil {
+			return 0, err
+		}
+		for _, b := range plan.Renamed {
+			if _, err := fmt.Fprintf(w, "  %s: %s -> %s\n", b.Name, b.Upstream, b.SuggestedUpstream); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(plan.Unrelated) > 0 {
+		if _, err := fmt.Fprintf(w, "Keeping branches with no history in common with the default branch (--include-unrelated to sweep them): %s\n\n", strings.Join(plan.Unrelated, ", ")); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(plan.Stashed) > 0 {
+		if _, err := fmt.Fprintln(w, "Keeping branches referenced by stash entries (--include-stashed to sweep them):"); err != nil {
+			return 0, err
+		}
+		for _, b := range plan.Stashed {
+			if _, err := fmt.Fprintf(w, "  %s (%s)\n", b.Name, strings.Join(b.Stashes, ", ")); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(plan.RemovedRemotes) > 0 {
+		if _, err := fmt.Fprintln(w, "Remote-tracking refs of remotes that are no longer configured (to remove):"); err != nil {
+			return 0, err
+		}
+		for _, rm := range plan.RemovedRemotes {
+			if _, err := fmt.Fprintf(w, "  refs/remotes/%s/ (%d ref(s))\n", rm.Name, len(rm.Refs)); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return 0, err
+		}
+	}
+
+	if len(plan.OrphanedConfigs) > 0 {
+		if _, err := fmt.Fprintln(w, "Config sections of branches that no longer exist (to remove):"); err != nil {
+			return 0, err
+		}
+		for _, name := range plan.OrphanedConfigs {
+			if _, err := fmt.Fprintf(w, "  branch.%s\n", name); err != nil {
+				return 0, err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return 0, err
+		}
+	}
+
 	if len(plan.Candidates) == 0 {
 		if _, err := fmt.Fprintln(w, "nothing to sweep, local branches are clean"); err != nil {
 			return 0, err
 		}
 		return 0, nil
 	}
-	if _, err := fmt.Fprintln(w, "The following local branches have a gone upstream:"); err != nil {
-		return 0, err
-	}
+	var gone, stale []git.Branch
 	for _, b := range plan.Candidates {
-		if _, err := fmt.Fprintf(w, "  %s\n", b.Name); err != nil {
+		if b.IsGone {
+			gone = append(gone, b)
+		} else {
+			stale = append(stale, b)
+		}
+	}
+	if len(gone) > 0 {
+		if _, err := fmt.Fprintln(w, "The following local branches have a gone upstream:"); err != nil {
 			return 0, err
 		}
+		for _, b := range gone {
+			if _, err := fmt.Fprintf(w, "  %s\n", describeCandidate(b)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	if len(stale) > 0 {
+		if _, err := fmt.Fprintln(w, "The following local branches have not been checked out recently:"); err != nil {
+			return 0, err
+		}
+		for _, b := range stale {
+			if _, err := fmt.Fprintf(w, "  %s\n", describeCandidate(b)); err != nil {
+				return 0, err
+			}
+		}
+	}
+	for _, b := range plan.Candidates {
+		if deps := plan.Dependents[b.Name]; len(deps) > 0 {
+			if _, err := fmt.Fprintf(w, "warning: %s is the base of %s (--repoint-stacked re-points them to the default branch)\n", b.Name, strings.Join(deps, ", ")); err != nil {
+				return 0, err
+			}
+		}
 	}
 	if _, err := fmt.Fprintf(w, "\n(%d to delete)\n", len(plan.Candidates)); err != nil {
 		return 0, err
 	}
 	return len(plan.Candidates), nil
 }
+
+// describeCandidate renders a plan entry as its name followed by any notes,
+// e.g. "feature/x (last checked out 2026-01-02; stash@{0})".
+func describeCandidate(b git.Branch) string {
+	var notes []string
+	if !b.IsGone && !b.LastCheckout.IsZero() {
+		notes = append(notes, "last checked out "+b.LastCheckout.Format("2006-01-02"))
+	}
+	if b.MissingRemote != "" {
+		notes = append(notes, "remote '"+b.MissingRemote+"' removed")
+	}
+	if b.Unrelated {
+		notes = append(notes, "no shared history")
+	}
+	notes = append(notes, b.Stashes...)
+	if len(notes) == 0 {
+		return b.Name
+	}
+	return b.Name + " (" + strings.Join(notes, "; ") + ")"
+}
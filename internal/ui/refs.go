@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+	"github.com/jmelosegui/git-sweep/internal/sweep"
+)
+
+// PrintRefPlan prints a sweep.RefPlan either as JSON or a human-readable
+// summary. It returns the number of candidates printed in the human-readable
+// mode.
+func PrintRefPlan(plan sweep.RefPlan, opts Options) (int, error) {
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return 0, enc.Encode(plan)
+	}
+	w := os.Stdout
+	if len(plan.Candidates) == 0 {
+		if _, err := fmt.Fprintln(w, "nothing to sweep, no stale refs in the configured namespaces"); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if _, err := fmt.Fprintln(w, "The following refs are stale:"); err != nil {
+		return 0, err
+	}
+	for _, c := range plan.Candidates {
+		if _, err := fmt.Fprintf(w, "  %s (%s; %s)\n", c.Ref, git.ShortSHA(c.Object), c.Reason); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\n(%d to delete)\n", len(plan.Candidates)); err != nil {
+		return 0, err
+	}
+	return len(plan.Candidates), nil
+}
+
+// PrintRefDeletionResult lists the deleted refs with the object each pointed
+// to, so they can be recreated with `git update-ref <ref> <object>`.
+func PrintRefDeletionResult(plan sweep.RefPlan) error {
+	w := os.Stdout
+	if _, err := fmt.Fprintf(w, "Deleted %d ref(s):\n", len(plan.Candidates)); err != nil {
+		return err
+	}
+	for _, c := range plan.Candidates {
+		if _, err := fmt.Fprintf(w, "  - %s (was %s)\n", c.Ref, git.ShortSHA(c.Object)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+	"github.com/jmelosegui/git-sweep/internal/sweep"
+)
+
+// PrintSyncResult prints which branches --sync fast-forwarded (or would, in a
+// dry run), which have diverged from their upstream and which were skipped.
+func PrintSyncResult(res sweep.SyncResult, dryRun bool) error {
+	w := os.Stdout
+	if len(res.Updated) == 0 && len(res.Diverged) == 0 && len(res.Skipped) == 0 {
+		_, err := fmt.Fprintln(w, "All branches are up to date with their upstream.")
+		return err
+	}
+	if len(res.Updated) > 0 {
+		verb := "Fast-forwarded"
+		if dryRun {
+			verb = "Would fast-forward"
+		}
+		if _, err := fmt.Fprintf(w, "%s %d branch(es):\n", verb, len(res.Updated)); err != nil {
+			return err
+		}
+		for _, s := range res.Updated {
+			if _, err := fmt.Fprintf(w, "  - %s %s..%s (%s)\n", s.Name, git.ShortSHA(s.From), git.ShortSHA(s.To), s.Upstream); err != nil {
+				return err
+			}
+		}
+	}
+	if len(res.Diverged) > 0 {
+		if _, err := fmt.Fprintf(w, "Diverged from upstream (%d):\n", len(res.Diverged)); err != nil {
+			return err
+		}
+		for _, name := range res.Diverged {
+			if _, err := fmt.Fprintf(w, "  - %s\n", name); err != nil {
+				return err
+			}
+		}
+	}
+	if len(res.Skipped) > 0 {
+		if _, err := fmt.Fprintf(w, "Skipped (%d):\n", len(res.Skipped)); err != nil {
+			return err
+		}
+		names := make([]string, 0, len(res.Skipped))
+		for name := range res.Skipped {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "  - %s: %s\n", name, res.Skipped[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/jmelosegui/git-sweep/internal/git"
+	"github.com/jmelosegui/git-sweep/internal/sweep"
+)
+
+// PrintTagPlan prints a sweep.TagPlan either as JSON or a human-readable
+// summary. It returns the number of candidates printed in the human-readable
+// mode.
+func PrintTagPlan(plan sweep.TagPlan, opts Options) (int, error) {
+	if opts.JSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return 0, enc.Encode(plan)
+	}
+	w := os.Stdout
+	if len(plan.Candidates) == 0 {
+		if _, err := fmt.Fprintf(w, "nothing to sweep, every local tag exists on %s\n", plan.Remote); err != nil {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if _, err := fmt.Fprintf(w, "The following local tags do not exist on %s:\n", plan.Remote); err != nil {
+		return 0, err
+	}
+	for _, t := range plan.Candidates {
+		if _, err := fmt.Fprintf(w, "  %s (%s)\n", t.Name, git.ShortSHA(t.Object)); err != nil {
+			return 0, err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "\n(%d to delete)\n", len(plan.Candidates)); err != nil {
+		return 0, err
+	}
+	return len(plan.Candidates), nil
+}
+
+// PrintTagDeletionResult lists the deleted tags with the object each pointed
+// to, so they can be recreated with `git tag <name> <object>`.
+func PrintTagDeletionResult(plan sweep.TagPlan) error {
+	w := os.Stdout
+	if _, err := fmt.Fprintf(w, "Deleted %d tag(s):\n", len(plan.Candidates)); err != nil {
+		return err
+	}
+	for _, t := range plan.Candidates {
+		if _, err := fmt.Fprintf(w, "  - %s (was %s)\n", t.Name, git.ShortSHA(t.Object)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -184,6 +185,1196 @@ func TestForceDeleteUnmergedGoneBranch(t *testing.T) {
 	}
 }
 
+// TestSafeDeletionEscalation verifies the default deletion policy: a branch
+// merged into origin/main (but not into the stale local HEAD) is escalated to
+// -D, while an unmerged branch with unpushed commits is left for --force.
+func TestSafeDeletionEscalation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	for _, name := range []string{"feat/merged", "feat/unmerged"} {
+		runGit(t, localPath, "checkout", "-b", name, "main")
+		writeFile(t, filepath.Join(localPath, strings.ReplaceAll(name, "/", "_")+".txt"), name+"\n")
+		runGit(t, localPath, "add", ".")
+		runGit(t, localPath, "commit", "-m", name)
+		runGit(t, localPath, "push", "-u", "origin", name)
+	}
+	// Merge feat/merged on the remote only; local main stays behind.
+	runGit(t, localPath, "push", "origin", "feat/merged:main")
+	runGit(t, localPath, "checkout", "main")
+	runGit(t, localPath, "push", "origin", ":feat/merged", ":feat/unmerged")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", plan.Candidates)
+	}
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if res.Methods["feat/merged"] != sweeppkg.MethodMerged {
+		t.Fatalf("expected feat/merged to be force-deleted as merged, got %+v", res)
+	}
+	if _, failed := res.Failed["feat/unmerged"]; !failed {
+		t.Fatalf("expected feat/unmerged to require --force, got %+v", res)
+	}
+}
+
+// TestMovedBranchIsLeftAlone verifies the compare-and-swap deletion: a branch
+// that gains a commit between planning and execution is not deleted, while the
+// other candidates are, and their branch config sections are removed.
+func TestMovedBranchIsLeftAlone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	for _, name := range []string{"feat/moves", "feat/stays"} {
+		runGit(t, localPath, "checkout", "-b", name, "main")
+		runGit(t, localPath, "push", "-u", "origin", name)
+		runGit(t, localPath, "push", "origin", ":"+name)
+	}
+	runGit(t, localPath, "checkout", "main")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 2 {
+		t.Fatalf("expected 2 candidates, got %+v", plan.Candidates)
+	}
+
+	// Move feat/moves after planning.
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "late work")
+	runGit(t, localPath, "branch", "-f", "feat/moves", "main")
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{ForceDelete: true})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if _, failed := res.Failed["feat/moves"]; !failed || len(res.Deleted) != 1 || res.Deleted[0] != "feat/stays" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	runGit(t, localPath, "show-ref", "--verify", "--quiet", "refs/heads/feat/moves")
+	cmd := exec.Command("git", "config", "--get", "branch.feat/stays.remote")
+	cmd.Dir = localPath
+	if err := cmd.Run(); err == nil {
+		t.Fatalf("expected branch config of feat/stays to be removed")
+	}
+}
+
+// TestArchiveRestoreAndExpire verifies that --archive moves a swept branch to
+// refs/sweep-archive/<date>/<name>, that it can be restored, and that expiry
+// removes entries older than the given age.
+func TestArchiveRestoreAndExpire(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "checkout", "-b", "feat/keep", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "unmerged work")
+	runGit(t, localPath, "push", "-u", "origin", "feat/keep")
+	runGit(t, localPath, "push", "origin", ":feat/keep")
+	runGit(t, localPath, "checkout", "main")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	archivedAt := time.Date(2026, 1, 31, 12, 0, 0, 0, time.Local)
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{ForceDelete: true, Archive: sweeppkg.ArchiveRefs, Now: archivedAt})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	wantRef := "refs/sweep-archive/2026-01-31/feat/keep"
+	if res.Archived["feat/keep"] != wantRef {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	runGit(t, localPath, "show-ref", "--verify", "--quiet", wantRef)
+
+	if _, err := sweeppkg.RestoreArchived(ctx, r, "feat/keep"); err != nil {
+		t.Fatalf("RestoreArchived error: %v", err)
+	}
+	runGit(t, localPath, "show-ref", "--verify", "--quiet", "refs/heads/feat/keep")
+	if got := gitOutput(t, localPath, "config", "--get", "branch.feat/keep.merge"); got != "refs/heads/feat/keep" {
+		t.Fatalf("restored branch lost its upstream config: %q", got)
+	}
+
+	// Archive again and expire relative to 100 days later.
+	runGit(t, localPath, "update-ref", wantRef, "feat/keep")
+	runGit(t, localPath, "update-ref", "-d", "refs/heads/feat/keep")
+	expired, err := sweeppkg.ExpireArchive(ctx, r, 90*24*time.Hour, archivedAt.Add(100*24*time.Hour))
+	if err != nil {
+		t.Fatalf("ExpireArchive error: %v", err)
+	}
+	if len(expired) != 1 || expired[0].Ref != wantRef {
+		t.Fatalf("unexpected expiry: %+v", expired)
+	}
+	entries, err := sweeppkg.ListArchive(ctx, r)
+	if err != nil || len(entries) != 0 {
+		t.Fatalf("expected empty archive, got %+v (%v)", entries, err)
+	}
+	cfg, err := gitpkg.BranchConfig(ctx, r)
+	if err != nil {
+		t.Fatalf("BranchConfig: %v", err)
+	}
+	if _, ok := cfg["feat/keep"]; ok {
+		t.Fatalf("expired branch kept its config: %v", cfg)
+	}
+}
+
+// TestJournalUndoRestoresBranchAndUpstream verifies that a journaled sweep can
+// be undone: the branch comes back at its old tip with its upstream config. A
+// later run that deleted nothing leaves no journal entry in the way.
+func TestJournalUndoRestoresBranchAndUpstream(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "checkout", "-b", "feat/undo", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "work")
+	runGit(t, localPath, "push", "-u", "origin", "feat/undo")
+	runGit(t, localPath, "push", "origin", ":feat/undo")
+	runGit(t, localPath, "checkout", "main")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{ForceDelete: true, Journal: true, CommandLine: []string{"git-sweep", "-y"}})
+	if err != nil || len(res.Deleted) != 1 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+	tip := res.Tips["feat/undo"]
+
+	// The next run deletes nothing: its only branch moves after planning.
+	runGit(t, localPath, "checkout", "-b", "feat/moved", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/moved")
+	runGit(t, localPath, "push", "origin", ":feat/moved")
+	runGit(t, localPath, "checkout", "main")
+	plan, err = sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	moved := gitOutput(t, localPath, "commit-tree", "-m", "moved", "-p", "feat/moved", "HEAD^{tree}")
+	runGit(t, localPath, "update-ref", "refs/heads/feat/moved", moved)
+	res, err = sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{ForceDelete: true, Journal: true})
+	if err != nil || len(res.Deleted) != 0 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+
+	undone, err := sweeppkg.Undo(ctx, r)
+	if err != nil || len(undone.Restored) != 1 || undone.Restored[0].Name != "feat/undo" {
+		t.Fatalf("Undo: %+v, %v", undone, err)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "refs/heads/feat/undo"); got != tip {
+		t.Fatalf("restored tip %s, want %s", got, tip)
+	}
+	if got := gitOutput(t, localPath, "config", "--get", "branch.feat/undo.merge"); got != "refs/heads/feat/undo" {
+		t.Fatalf("upstream config not restored: %q", got)
+	}
+	if _, err := sweeppkg.Undo(ctx, r); !errors.Is(err, sweeppkg.ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+	again, err := sweeppkg.RestoreFromJournal(ctx, r, "feat/undo")
+	if err != nil || len(again.Skipped) != 1 {
+		t.Fatalf("expected existing branch to be skipped: %+v, %v", again, err)
+	}
+}
+
+// TestRecoverBranchDeletedByHand verifies that a branch deleted with
+// `git branch -D` is found through the HEAD reflog and can be recreated.
+func TestRecoverBranchDeletedByHand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "checkout", "-b", "feat/lost", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "lost work")
+	tip := gitOutput(t, localPath, "rev-parse", "HEAD")
+	runGit(t, localPath, "checkout", "main")
+	runGit(t, localPath, "branch", "-D", "feat/lost")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	found, err := sweeppkg.FindRecoverable(ctx, r, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("FindRecoverable error: %v", err)
+	}
+	var lost *sweeppkg.Recoverable
+	for i := range found {
+		if found[i].Name == "main" {
+			t.Fatalf("existing branch offered for recovery: %+v", found[i])
+		}
+		if found[i].Name == "feat/lost" {
+			lost = &found[i]
+		}
+	}
+	if lost == nil || lost.Tip != tip || lost.Subject != "lost work" || lost.Source != sweeppkg.SourceHeadReflog {
+		t.Fatalf("feat/lost not found at %s: %+v", tip, found)
+	}
+
+	res, err := sweeppkg.Recover(ctx, r, []sweeppkg.Recoverable{*lost})
+	if err != nil || len(res.Restored) != 1 {
+		t.Fatalf("Recover: %+v, %v", res, err)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "refs/heads/feat/lost"); got != tip {
+		t.Fatalf("recovered tip %s, want %s", got, tip)
+	}
+}
+
+// TestRescuePushBeforeDeletion verifies that --rescue-remote pushes a gone
+// branch with unpushed commits to rescue/<user>/<branch> and then deletes it
+// without --force.
+func TestRescuePushBeforeDeletion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "checkout", "-b", "feat/rescue", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "pushed")
+	runGit(t, localPath, "push", "-u", "origin", "feat/rescue")
+	runGit(t, localPath, "push", "origin", ":feat/rescue")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "never pushed")
+	tip := gitOutput(t, localPath, "rev-parse", "HEAD")
+	runGit(t, localPath, "checkout", "main")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{RescueRemote: "origin"})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if len(res.Deleted) != 1 || res.Methods["feat/rescue"] != sweeppkg.MethodRescued {
+		t.Fatalf("expected feat/rescue to be rescued and deleted: %+v", res)
+	}
+	if got := res.Rescued["feat/rescue"]; got != "origin/rescue/test/feat/rescue" {
+		t.Fatalf("rescue branch %q", got)
+	}
+	remote := gitOutput(t, localPath, "ls-remote", "origin", "refs/heads/rescue/test/feat/rescue")
+	if !strings.HasPrefix(remote, tip) {
+		t.Fatalf("rescue branch on remote is %q, want %s", remote, tip)
+	}
+}
+
+// TestSwitchBackWhenCurrentBranchRefused verifies that when the deletion policy
+// refuses the branch --switch moved off, the sweep checks it out again.
+func TestSwitchBackWhenCurrentBranchRefused(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "checkout", "-b", "feat/unmerged", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "feature")
+	runGit(t, localPath, "push", "-u", "origin", "feat/unmerged")
+	runGit(t, localPath, "push", "origin", ":feat/unmerged")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, Switch: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if plan.SwitchTo != "main" {
+		t.Fatalf("expected to switch to main: %+v", plan)
+	}
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if res.SwitchedTo != "" || len(res.Deleted) != 0 || res.Failed["feat/unmerged"] == nil {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "--short", "HEAD"); got != "feat/unmerged" {
+		t.Fatalf("HEAD is on %s, want feat/unmerged", got)
+	}
+}
+
+// TestSwitchBackWhenSweepFails verifies that a sweep that fails after --switch
+// moved off the current branch, here because the bundle cannot be written,
+// returns the error and checks the branch out again.
+func TestSwitchBackWhenSweepFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "checkout", "-b", "feat/merged", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/merged")
+	runGit(t, localPath, "push", "origin", ":feat/merged")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, Switch: true})
+	if err != nil || plan.SwitchTo != "main" {
+		t.Fatalf("BuildPlan: %+v, %v", plan, err)
+	}
+	bundlePath := filepath.Join(t.TempDir(), "missing", "swept.bundle")
+	if _, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{BundlePath: bundlePath}); err == nil {
+		t.Fatal("expected the bundle write to fail")
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "--short", "HEAD"); got != "feat/merged" {
+		t.Fatalf("HEAD is on %s, want feat/merged", got)
+	}
+	runGit(t, localPath, "show-ref", "--verify", "--quiet", "refs/heads/feat/merged")
+}
+
+// TestSwitchSweepsGoneCurrentBranch verifies that --switch checks out and
+// fast-forwards the default branch when the current branch is gone, then
+// sweeps the old current branch.
+func TestSwitchSweepsGoneCurrentBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "checkout", "-b", "feat/merged", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "feature")
+	runGit(t, localPath, "push", "-u", "origin", "feat/merged")
+	// The PR is merged on the server and its branch deleted.
+	runGit(t, localPath, "push", "origin", "feat/merged:main", ":feat/merged")
+	tip := gitOutput(t, localPath, "rev-parse", "HEAD")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, Switch: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if plan.SwitchTo != "main" || len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/merged" {
+		t.Fatalf("expected to switch to main and sweep feat/merged: %+v", plan)
+	}
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if res.SwitchedTo != "main" || res.FastForwardErr != nil || len(res.Deleted) != 1 {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "--short", "HEAD"); got != "main" {
+		t.Fatalf("HEAD is on %s, want main", got)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "main"); got != tip {
+		t.Fatalf("main at %s, want fast-forwarded to %s", got, tip)
+	}
+
+	// A dirty worktree keeps the current branch.
+	runGit(t, localPath, "checkout", "-b", "feat/dirty")
+	runGit(t, localPath, "push", "-u", "origin", "feat/dirty")
+	runGit(t, localPath, "push", "origin", ":feat/dirty")
+	writeFile(t, filepath.Join(localPath, "README.md"), "changed\n")
+	plan, err = sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, Switch: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if plan.SwitchTo != "" || plan.SwitchRefused == "" || len(plan.Candidates) != 0 {
+		t.Fatalf("expected switch to be refused: %+v", plan)
+	}
+}
+
+// TestSyncFastForwardsBehindBranches verifies that Sync fast-forwards the
+// current branch and other branches strictly behind their upstream, and
+// reports diverged ones.
+func TestSyncFastForwardsBehindBranches(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	// behind: its upstream has one more commit.
+	runGit(t, localPath, "checkout", "-b", "behind", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "upstream work")
+	runGit(t, localPath, "push", "-u", "origin", "behind")
+	behindTip := gitOutput(t, localPath, "rev-parse", "HEAD")
+	runGit(t, localPath, "reset", "--hard", "HEAD~1")
+	// diverged: local and upstream each have a commit the other lacks.
+	runGit(t, localPath, "checkout", "-b", "diverged", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "theirs")
+	runGit(t, localPath, "push", "-u", "origin", "diverged")
+	runGit(t, localPath, "reset", "--hard", "HEAD~1")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "ours")
+	// main (current) is behind as well.
+	runGit(t, localPath, "checkout", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "merged PR")
+	runGit(t, localPath, "push", "origin", "main")
+	mainTip := gitOutput(t, localPath, "rev-parse", "HEAD")
+	runGit(t, localPath, "reset", "--hard", "HEAD~1")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	dry, err := sweeppkg.Sync(ctx, r, true)
+	if err != nil || len(dry.Updated) != 2 {
+		t.Fatalf("dry run: %+v, %v", dry, err)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "behind"); got == behindTip {
+		t.Fatalf("dry run moved behind")
+	}
+
+	res, err := sweeppkg.Sync(ctx, r, false)
+	if err != nil {
+		t.Fatalf("Sync error: %v", err)
+	}
+	if len(res.Updated) != 2 || len(res.Diverged) != 1 || res.Diverged[0] != "diverged" {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "behind"); got != behindTip {
+		t.Fatalf("behind at %s, want %s", got, behindTip)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "HEAD"); got != mainTip {
+		t.Fatalf("main at %s, want %s", got, mainTip)
+	}
+}
+
+// TestDefaultBranchRenameMigratesLocalBranch verifies that a local master
+// whose remote default was renamed to main is renamed and re-pointed instead
+// of being swept, and that the remote HEAD ref follows.
+func TestDefaultBranchRenameMigratesLocalBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	remotePath := filepath.Join(filepath.Dir(localPath), "remote.git")
+	runGit(t, localPath, "branch", "-m", "main", "master")
+	runGit(t, localPath, "push", "-u", "origin", "master", ":main")
+	runGit(t, remotePath, "symbolic-ref", "HEAD", "refs/heads/master")
+	runGit(t, localPath, "remote", "set-head", "origin", "master")
+	// The server renames master to main.
+	runGit(t, localPath, "push", "origin", "master:main")
+	runGit(t, remotePath, "symbolic-ref", "HEAD", "refs/heads/main")
+	runGit(t, localPath, "push", "origin", ":master")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	want := sweeppkg.DefaultRename{Remote: "origin", From: "master", To: "main", Branch: "master"}
+	if plan.DefaultRename == nil || *plan.DefaultRename != want {
+		t.Fatalf("DefaultRename = %+v, want %+v", plan.DefaultRename, want)
+	}
+	if len(plan.Candidates) != 0 {
+		t.Fatalf("renamed default branch should not be swept: %+v", plan.Candidates)
+	}
+
+	if _, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{}); err != nil {
+		t.Fatalf("ExecuteDeletions error: %v", err)
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "--short", "HEAD"); got != "main" {
+		t.Fatalf("current branch %s, want main", got)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "--abbrev-ref", "main@{upstream}"); got != "origin/main" {
+		t.Fatalf("main tracks %s, want origin/main", got)
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "refs/remotes/origin/HEAD"); got != "refs/remotes/origin/main" {
+		t.Fatalf("origin/HEAD is %s", got)
+	}
+}
+
+// TestRenamedUpstreamIsSuggestedAndRetracked verifies that a gone branch whose
+// remote branch was renamed is offered for re-tracking instead of deletion,
+// while a merged gone branch is still swept.
+func TestRenamedUpstreamIsSuggestedAndRetracked(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "branch", "feat/merged", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/merged")
+	runGit(t, localPath, "push", "origin", ":feat/merged")
+	runGit(t, localPath, "checkout", "-b", "feat/old", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "work in progress")
+	runGit(t, localPath, "push", "-u", "origin", "feat/old")
+	runGit(t, localPath, "checkout", "main")
+	// The branch is renamed on the forge.
+	runGit(t, localPath, "push", "origin", "feat/old:feat/new", ":feat/old")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Renamed) != 1 || plan.Renamed[0].Name != "feat/old" || plan.Renamed[0].SuggestedUpstream != "origin/feat/new" {
+		t.Fatalf("expected feat/old to be re-tracked to origin/feat/new: %+v", plan.Renamed)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/merged" {
+		t.Fatalf("expected only feat/merged to be swept: %+v", plan.Candidates)
+	}
+
+	if failed := sweeppkg.Retrack(ctx, r, plan.Renamed); len(failed) != 0 {
+		t.Fatalf("Retrack failed: %v", failed)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "--abbrev-ref", "feat/old@{upstream}"); got != "origin/feat/new" {
+		t.Fatalf("feat/old tracks %s, want origin/feat/new", got)
+	}
+}
+
+// TestStackedBranchIsRepointedWhenBaseIsSwept verifies that the plan reports
+// branches stacked on a candidate and that they are re-pointed to the default
+// branch when their base is deleted.
+func TestStackedBranchIsRepointedWhenBaseIsSwept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "branch", "feat/base", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/base")
+	runGit(t, localPath, "push", "origin", ":feat/base")
+	runGit(t, localPath, "branch", "--track", "feat/top", "feat/base")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/base" {
+		t.Fatalf("expected only feat/base to be swept: %+v", plan.Candidates)
+	}
+	if deps := plan.Dependents["feat/base"]; len(deps) != 1 || deps[0] != "feat/top" {
+		t.Fatalf("expected feat/top as dependent of feat/base: %v", plan.Dependents)
+	}
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{RepointDependents: true})
+	if err != nil || len(res.Deleted) != 1 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+	if got := gitOutput(t, localPath, "rev-parse", "--abbrev-ref", "feat/top@{upstream}"); got != "origin/main" {
+		t.Fatalf("feat/top tracks %s, want origin/main", got)
+	}
+}
+
+// TestOrphanBranchIsProtected verifies that a gone branch created with
+// --orphan is kept unless unrelated branches are explicitly included.
+func TestOrphanBranchIsProtected(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "checkout", "--orphan", "gh-pages")
+	runGit(t, localPath, "commit", "-m", "pages")
+	runGit(t, localPath, "push", "-u", "origin", "gh-pages")
+	runGit(t, localPath, "checkout", "main")
+	runGit(t, localPath, "push", "origin", ":gh-pages")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, ProtectUnrelated: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 0 || len(plan.Unrelated) != 1 || plan.Unrelated[0] != "gh-pages" {
+		t.Fatalf("expected gh-pages to be protected: %+v, %v", plan.Candidates, plan.Unrelated)
+	}
+
+	plan, err = sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || !plan.Candidates[0].Unrelated {
+		t.Fatalf("expected gh-pages to be a marked candidate: %+v", plan.Candidates)
+	}
+}
+
+// TestSymbolicBranchIsNotSwept verifies that a symbolic branch and the gone
+// branch it points to are both left in place.
+func TestSymbolicBranchIsNotSwept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "branch", "feat/target", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/target")
+	runGit(t, localPath, "push", "origin", ":feat/target")
+	runGit(t, localPath, "branch", "feat/gone", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/gone")
+	runGit(t, localPath, "push", "origin", ":feat/gone")
+	runGit(t, localPath, "symbolic-ref", "refs/heads/alias", "refs/heads/feat/target")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/gone" {
+		t.Fatalf("expected only feat/gone to be swept: %+v", plan.Candidates)
+	}
+	if _, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{}); err != nil {
+		t.Fatalf("ExecuteDeletions: %v", err)
+	}
+	if got := gitOutput(t, localPath, "symbolic-ref", "refs/heads/alias"); got != "refs/heads/feat/target" {
+		t.Fatalf("alias now points to %q", got)
+	}
+	gitOutput(t, localPath, "rev-parse", "--verify", "refs/heads/feat/target")
+}
+
+// TestPruneConfigRemovesOrphanedSections verifies that config sections of
+// branches deleted behind git's back are listed in the plan and removed.
+func TestPruneConfigRemovesOrphanedSections(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "branch", "--track", "feat/manual", "origin/main")
+	runGit(t, localPath, "update-ref", "-d", "refs/heads/feat/manual")
+	runGit(t, localPath, "branch", "local-only")
+	runGit(t, localPath, "config", "branch.local-only.description", "kept")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, PruneConfig: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.OrphanedConfigs) != 1 || plan.OrphanedConfigs[0] != "feat/manual" {
+		t.Fatalf("expected feat/manual config to be orphaned: %v", plan.OrphanedConfigs)
+	}
+	if failed := sweeppkg.PruneBranchConfigs(ctx, r, plan.OrphanedConfigs); len(failed) != 0 {
+		t.Fatalf("PruneBranchConfigs: %v", failed)
+	}
+	cfg, err := gitpkg.BranchConfig(ctx, r)
+	if err != nil {
+		t.Fatalf("BranchConfig: %v", err)
+	}
+	if _, ok := cfg["feat/manual"]; ok {
+		t.Fatalf("feat/manual config still present: %v", cfg)
+	}
+	if _, ok := cfg["local-only"]; !ok {
+		t.Fatalf("local-only config was removed: %v", cfg)
+	}
+}
+
+// TestRemovedRemoteIsSwept verifies that refs of a remote that was removed
+// from the config are offered for removal and that branches tracking it are
+// swept like gone branches.
+func TestRemovedRemoteIsSwept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "update-ref", "refs/remotes/old/main", "main")
+	runGit(t, localPath, "symbolic-ref", "refs/remotes/old/HEAD", "refs/remotes/old/main")
+	runGit(t, localPath, "branch", "feat/old", "main")
+	runGit(t, localPath, "config", "branch.feat/old.remote", "old")
+	runGit(t, localPath, "config", "branch.feat/old.merge", "refs/heads/feat/old")
+
+	runGit(t, localPath, "update-ref", "refs/remotes/git-svn", "main")
+	runGit(t, localPath, "config", "svn-remote.svn.fetch", "trunk:refs/remotes/git-svn")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.RemovedRemotes) != 0 {
+		t.Fatalf("removed remotes listed without PruneRemotes: %+v", plan.RemovedRemotes)
+	}
+	plan, err = sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, PruneRemotes: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/old" || plan.Candidates[0].MissingRemote != "old" {
+		t.Fatalf("expected feat/old to be swept: %+v", plan.Candidates)
+	}
+	if len(plan.RemovedRemotes) != 1 || plan.RemovedRemotes[0].Name != "old" || len(plan.RemovedRemotes[0].Refs) != 2 {
+		t.Fatalf("expected the refs of old to be listed: %+v", plan.RemovedRemotes)
+	}
+
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{})
+	if err != nil || len(res.Deleted) != 1 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+	if err := sweeppkg.PruneRemovedRemotes(ctx, r, plan.RemovedRemotes); err != nil {
+		t.Fatalf("PruneRemovedRemotes: %v", err)
+	}
+	if refs := gitOutput(t, localPath, "for-each-ref", "refs/remotes/old"); refs != "" {
+		t.Fatalf("refs of old remain: %s", refs)
+	}
+	gitOutput(t, localPath, "rev-parse", "--verify", "refs/remotes/origin/main")
+	gitOutput(t, localPath, "rev-parse", "--verify", "refs/remotes/git-svn")
+}
+
+// TestTagsMissingFromRemoteAreSwept verifies that only local tags absent
+// from the remote are planned, honoring exclusions, and that they are deleted.
+func TestTagsMissingFromRemoteAreSwept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "tag", "v1.0")
+	runGit(t, localPath, "push", "origin", "v1.0")
+	runGit(t, localPath, "tag", "-a", "-m", "local", "v1.1-rc")
+	runGit(t, localPath, "tag", "local/keep")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildTagPlan(ctx, r, sweeppkg.TagOptions{Remote: "origin", ExcludePattern: "^local/"})
+	if err != nil {
+		t.Fatalf("BuildTagPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "v1.1-rc" {
+		t.Fatalf("expected only v1.1-rc to be swept: %+v", plan.Candidates)
+	}
+	if err := sweeppkg.DeleteTags(ctx, r, plan); err != nil {
+		t.Fatalf("DeleteTags: %v", err)
+	}
+	if got := gitOutput(t, localPath, "tag", "--list"); got != "local/keep\nv1.0" {
+		t.Fatalf("unexpected remaining tags: %q", got)
+	}
+}
+
+// TestConfiguredNamespaceIsSwept verifies that a [sweepref] rule selects refs
+// of its namespace that are missing on the remote and that they are deleted.
+func TestConfiguredNamespaceIsSwept(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "push", "origin", "main:refs/review/1")
+	runGit(t, localPath, "update-ref", "refs/review/1", "main")
+	runGit(t, localPath, "update-ref", "refs/review/2", "main")
+	runGit(t, localPath, "config", "sweepref.refs/review/.remote", "origin")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	rules, err := sweeppkg.NamespaceRules(ctx, r)
+	if err != nil || len(rules) != 1 || rules[0].Prefix != "refs/review/" || rules[0].Remote != "origin" {
+		t.Fatalf("NamespaceRules: %+v, %v", rules, err)
+	}
+	plan, err := sweeppkg.BuildRefPlan(ctx, r, rules, time.Now())
+	if err != nil {
+		t.Fatalf("BuildRefPlan error: %v", err)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Ref != "refs/review/2" {
+		t.Fatalf("expected only refs/review/2 to be swept: %+v", plan.Candidates)
+	}
+	if err := sweeppkg.DeleteStaleRefs(ctx, r, plan); err != nil {
+		t.Fatalf("DeleteStaleRefs: %v", err)
+	}
+	if got := gitOutput(t, localPath, "for-each-ref", "--format=%(refname)", "refs/review"); got != "refs/review/1" {
+		t.Fatalf("unexpected remaining refs: %q", got)
+	}
+}
+
+// TestStashProtectsOnlyOwningBranch verifies that a stash taken on a commit the
+// remote has does not protect every branch built on it, while a stash taken on
+// a detached commit only one branch has protects that branch and is listed in
+// the plan.
+func TestStashProtectsOnlyOwningBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	for _, name := range []string{"feat/a", "feat/b", "feat/wip"} {
+		runGit(t, localPath, "checkout", "-b", name, "main")
+		runGit(t, localPath, "commit", "--allow-empty", "-m", name)
+		runGit(t, localPath, "push", "-u", "origin", name)
+		runGit(t, localPath, "push", "origin", ":"+name)
+	}
+	runGit(t, localPath, "checkout", "--detach", "feat/wip")
+	writeFile(t, filepath.Join(localPath, "README.md"), "wip\n")
+	runGit(t, localPath, "stash")
+	runGit(t, localPath, "checkout", "main")
+	writeFile(t, filepath.Join(localPath, "README.md"), "main\n")
+	runGit(t, localPath, "stash")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true, ProtectStashed: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	var names []string
+	for _, b := range plan.Candidates {
+		names = append(names, b.Name)
+	}
+	if strings.Join(names, ",") != "feat/a,feat/b" {
+		t.Fatalf("unexpected candidates: %v", names)
+	}
+	if len(plan.Stashed) != 1 || plan.Stashed[0].Name != "feat/wip" || strings.Join(plan.Stashed[0].Stashes, ",") != "stash@{1}" {
+		t.Fatalf("unexpected stashed branches: %+v", plan.Stashed)
+	}
+}
+
+// TestRebaseInOtherWorktreeProtectsBranch verifies that a branch being rebased
+// in another worktree, which shows there as a detached HEAD, is not swept.
+func TestRebaseInOtherWorktreeProtectsBranch(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	for _, name := range []string{"feat/rebasing", "feat/idle"} {
+		runGit(t, localPath, "checkout", "-b", name, "main")
+		runGit(t, localPath, "commit", "--allow-empty", "-m", name)
+		runGit(t, localPath, "push", "-u", "origin", name)
+		runGit(t, localPath, "push", "origin", ":"+name)
+	}
+	runGit(t, localPath, "checkout", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "main moves on")
+
+	wtPath := filepath.Join(t.TempDir(), "wt")
+	runGit(t, localPath, "worktree", "add", wtPath, "feat/rebasing")
+	// The failing --exec stops the rebase halfway.
+	cmd := exec.Command("git", "rebase", "--exec", "false", "main")
+	cmd.Dir = wtPath
+	if out, err := cmd.CombinedOutput(); err == nil {
+		t.Fatalf("expected the rebase to stop:\n%s", out)
+	}
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if plan.Operation.Name != "" || len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/idle" {
+		t.Fatalf("expected only feat/idle to be swept: %+v", plan)
+	}
+}
+
+// TestBundleExportAndRestore verifies that --bundle exports every swept
+// branch, whether or not its tip is on a remote, and that they can be restored
+// from the bundle into a fresh clone, also when all of them are merged.
+func TestBundleExportAndRestore(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "checkout", "-b", "feat/unpushed", "main")
+	runGit(t, localPath, "commit", "--allow-empty", "-m", "only local")
+	runGit(t, localPath, "push", "-u", "origin", "feat/unpushed")
+	runGit(t, localPath, "push", "origin", ":feat/unpushed")
+	runGit(t, localPath, "checkout", "-b", "feat/onremote", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/onremote")
+	runGit(t, localPath, "push", "origin", ":feat/onremote")
+	runGit(t, localPath, "checkout", "main")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	bundlePath := filepath.Join(t.TempDir(), "swept.bundle")
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{ForceDelete: true, BundlePath: bundlePath})
+	if err != nil || len(res.Deleted) != 2 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+	if strings.Join(res.Bundled, ",") != "feat/onremote,feat/unpushed" {
+		t.Fatalf("unexpected bundled branches: %v", res.Bundled)
+	}
+
+	clonePath := filepath.Join(t.TempDir(), "clone")
+	runGit(t, filepath.Dir(clonePath), "clone", toFileURL(filepath.Join(filepath.Dir(localPath), "remote.git")), clonePath)
+	restored, err := sweeppkg.RestoreFromBundle(ctx, gitpkg.ExecRunner{WorkDir: clonePath}, bundlePath, nil)
+	if err != nil || len(restored.Restored) != 2 {
+		t.Fatalf("RestoreFromBundle: %+v, %v", restored, err)
+	}
+	if got := gitOutput(t, clonePath, "rev-parse", "refs/heads/feat/unpushed"); got != res.Tips["feat/unpushed"] {
+		t.Fatalf("restored tip %s, want %s", got, res.Tips["feat/unpushed"])
+	}
+
+	runGit(t, localPath, "checkout", "-b", "feat/merged", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat/merged")
+	runGit(t, localPath, "push", "origin", ":feat/merged")
+	runGit(t, localPath, "checkout", "main")
+	plan, err = sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	mergedPath := filepath.Join(t.TempDir(), "merged.bundle")
+	res, err = sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{BundlePath: mergedPath})
+	if err != nil || len(res.Deleted) != 1 || len(res.Bundled) != 1 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+	restored, err = sweeppkg.RestoreFromBundle(ctx, gitpkg.ExecRunner{WorkDir: clonePath}, mergedPath, nil)
+	if err != nil || len(restored.Restored) != 1 || restored.Restored[0].Name != "feat/merged" {
+		t.Fatalf("RestoreFromBundle: %+v, %v", restored, err)
+	}
+}
+
+// TestDetachedHeadProtectsBranchAtHead verifies that with a detached HEAD the
+// plan reports the detached state and protects a gone branch whose tip is the
+// detached commit, while other gone branches are still swept.
+func TestDetachedHeadProtectsBranchAtHead(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	for _, name := range []string{"feat/here", "feat/elsewhere"} {
+		runGit(t, localPath, "checkout", "-b", name, "main")
+		writeFile(t, filepath.Join(localPath, strings.ReplaceAll(name, "/", "_")+".txt"), name+"\n")
+		runGit(t, localPath, "add", ".")
+		runGit(t, localPath, "commit", "-m", name)
+		runGit(t, localPath, "push", "-u", "origin", name)
+		runGit(t, localPath, "push", "origin", ":"+name)
+	}
+	runGit(t, localPath, "checkout", "--detach", "feat/here")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if !plan.Detached || plan.CurrentBranch != "" {
+		t.Fatalf("expected detached plan, got %+v", plan)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat/elsewhere" {
+		t.Fatalf("expected only feat/elsewhere, got %+v", plan.Candidates)
+	}
+}
+
+// TestUnbornRepositoryYieldsEmptyPlan verifies that a repository without any
+// commits produces an empty plan instead of an error.
+func TestUnbornRepositoryYieldsEmptyPlan(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := filepath.Join(t.TempDir(), "local")
+	mustMkdir(t, localPath)
+	runGit(t, localPath, "init")
+
+	plan, err := sweeppkg.BuildPlan(ctx, gitpkg.ExecRunner{WorkDir: localPath}, sweeppkg.Options{Remote: "origin"})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if !plan.Unborn || len(plan.Candidates) != 0 {
+		t.Fatalf("expected empty unborn plan, got %+v", plan)
+	}
+}
+
+// TestOrphanCheckoutStillSweeps verifies that an unborn HEAD created with
+// `checkout --orphan` in a repository with branches does not hide them.
+func TestOrphanCheckoutStillSweeps(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git not available in PATH")
+		}
+	}
+
+	t.Parallel()
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	localPath := setupLocalWithRemote(t)
+	runGit(t, localPath, "remote", "set-head", "origin", "main")
+	runGit(t, localPath, "branch", "feat", "main")
+	runGit(t, localPath, "push", "-u", "origin", "feat")
+	runGit(t, localPath, "push", "origin", ":feat")
+	runGit(t, localPath, "checkout", "--orphan", "fresh")
+
+	r := gitpkg.ExecRunner{WorkDir: localPath}
+	plan, err := sweeppkg.BuildPlan(ctx, r, sweeppkg.Options{Remote: "origin", ProtectCurrent: true, ProtectUpstream: true})
+	if err != nil {
+		t.Fatalf("BuildPlan error: %v", err)
+	}
+	if plan.Unborn || plan.CurrentBranch != "fresh" || plan.HeadCommit != "" {
+		t.Fatalf("expected an unborn current branch in a populated repository: %+v", plan)
+	}
+	if len(plan.Candidates) != 1 || plan.Candidates[0].Name != "feat" {
+		t.Fatalf("expected feat to be swept: %+v", plan.Candidates)
+	}
+	res, err := sweeppkg.ExecuteDeletions(ctx, r, plan, sweeppkg.ExecuteOptions{})
+	if err != nil || len(res.Deleted) != 1 {
+		t.Fatalf("ExecuteDeletions: %+v, %v", res, err)
+	}
+}
+
+// setupLocalWithRemote creates a bare remote and a local clone-equivalent with
+// one commit on main pushed to origin, and returns the local path.
+func setupLocalWithRemote(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	remotePath := filepath.Join(tmp, "remote.git")
+	localPath := filepath.Join(tmp, "local")
+
+	runGit(t, tmp, "init", "--bare", remotePath)
+	mustMkdir(t, localPath)
+	runGit(t, localPath, "init")
+	runGit(t, localPath, "config", "user.name", "Test User")
+	runGit(t, localPath, "config", "user.email", "test@example.com")
+
+	writeFile(t, filepath.Join(localPath, "README.md"), "hello\n")
+	runGit(t, localPath, "add", ".")
+	runGit(t, localPath, "commit", "-m", "init")
+	runGit(t, localPath, "branch", "-M", "main")
+
+	runGit(t, localPath, "remote", "add", "origin", toFileURL(remotePath))
+	runGit(t, localPath, "push", "-u", "origin", "main")
+	return localPath
+}
+
 // runGit executes a git command in the given directory and fails the test with
 // a helpful message (including combined output) on error. This avoids hiding
 // errors that would otherwise appear only in subprocess output.
@@ -198,6 +1389,18 @@ func runGit(t *testing.T, dir string, args ...string) {
 	}
 }
 
+// gitOutput runs a git command in dir and returns its trimmed stdout.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v failed: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
 // writeFile writes a file with standard permissions and fails the test on error.
 func writeFile(t *testing.T, path string, data string) {
 	t.Helper()